@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"orbital/config"
+	"orbital/orbital"
+	"orbital/pkg/cryptographer"
+	"strings"
+	"time"
+)
+
+// adminClient call the running node over its admin socket. The socket peer credentials
+// authorize the calls, no secret key is needed
+type adminClient struct {
+	client  *http.Client
+	nodeKey string // Expected signer of the replies, from the config secret key
+}
+
+// dialAdmin connect to the admin socket of the local node. Fails with ErrNodeNotRunning when nothing answers
+func dialAdmin(ctx context.Context, cfg *config.Config) (*adminClient, error) {
+	socket := cfg.AdminSocket()
+	if socket == "" {
+		return nil, fmt.Errorf("%w:[admin socket disabled]", ErrNodeNotRunning)
+	}
+
+	c := &adminClient{
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socket)
+				},
+			},
+		},
+	}
+
+	if sk, err := cryptographer.NewPrivateKeyFromHex(cfg.SecretKey); err == nil {
+		c.nodeKey = sk.PublicKey().ToHex()
+	}
+
+	if _, err := c.health(ctx); err != nil {
+		return nil, fmt.Errorf("%w:[%v]", ErrNodeNotRunning, err)
+	}
+
+	return c, nil
+}
+
+// health read the liveness report. A 503 still returns the report
+func (c *adminClient) health(ctx context.Context) (*orbital.HealthResp, error) {
+	return c.probe(ctx, "/healthz")
+}
+
+// readiness read the readiness report, with every check
+func (c *adminClient) readiness(ctx context.Context) (*orbital.HealthResp, error) {
+	return c.probe(ctx, "/readyz")
+}
+
+func (c *adminClient) probe(ctx context.Context, path string) (*orbital.HealthResp, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://orbital"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode == http.StatusForbidden {
+		return nil, ErrAdminDenied
+	}
+
+	var health orbital.HealthResp
+	if err = json.NewDecoder(res.Body).Decode(&health); err != nil {
+		return nil, fmt.Errorf("%w:[%v]", ErrAdminReply, err)
+	}
+
+	return &health, nil
+}
+
+// call the RPC service/action with the bare request body and decode the signed reply into res
+func (c *adminClient) call(ctx context.Context, service, action string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("http://orbital/rpc/%s/%s", service, action)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w:[%v]", ErrNodeNotRunning, err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	// The socket guard refuses in plain text, RPC errors are signed JSON
+	if res.StatusCode == http.StatusForbidden && !strings.Contains(res.Header.Get("Content-Type"), "json") {
+		return ErrAdminDenied
+	}
+
+	var msg cryptographer.Message
+	if err = json.NewDecoder(res.Body).Decode(&msg); err != nil {
+		return fmt.Errorf("%w:[%v]", ErrAdminReply, err)
+	}
+
+	if valid, err := msg.Verify(); err != nil || !valid {
+		return fmt.Errorf("%w:[bad signature]", ErrAdminReply)
+	}
+	if signer := hex.EncodeToString(msg.PublicKey[:]); c.nodeKey != "" && signer != c.nodeKey {
+		return fmt.Errorf("%w:[signed by %s, not the node key]", ErrAdminReply, signer)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		var reply orbital.ErrorReply
+		if err = json.Unmarshal(msg.Body, &reply); err != nil || reply.Error == nil {
+			return fmt.Errorf("%w:[status %d]", ErrAdminReply, res.StatusCode)
+		}
+		return orbital.NewError(reply.Code, reply.Error.Type, reply.Error.Msg).WithDetails(reply.Error.Details)
+	}
+
+	if err = json.Unmarshal(msg.Body, out); err != nil {
+		return fmt.Errorf("%w:[%v]", ErrAdminReply, err)
+	}
+
+	return nil
+}
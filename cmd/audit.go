@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"orbital/config"
+	"orbital/domain"
+	"orbital/internal/audit"
+	"orbital/pkg/prompt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Inspect the audit log",
+	}
+
+	cmd.AddCommand(newAuditVerifyCmd())
+	cmd.AddCommand(newAuditExportCmd())
+
+	return cmd
+}
+
+func newAuditVerifyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify",
+		Short: "Check the hash chain of the audit log",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmdHeader("audit verify")
+
+			prompt.Bold(prompt.ColorYellow, "[ Verifying audit chain ]")
+			count, broken, err := auditVerify(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if broken != "" {
+				prompt.Err(prompt.NewLine("        %s"), broken)
+				prompt.Info(prompt.NewLine("        %d entries valid before it"), count)
+				return domain.ErrAuditChainBroken
+			}
+
+			prompt.Bold(prompt.ColorGreen, "        OK")
+			prompt.Info(prompt.NewLine("        %d entries checked"), count)
+			fmt.Println()
+
+			return nil
+		},
+	}
+}
+
+func newAuditExportCmd() *cobra.Command {
+	var (
+		format string
+		out    string
+		since  time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the audit log as JSON lines or CSV",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "jsonl" && format != "csv" {
+				return fmt.Errorf("%w:[%s]", ErrUnknownFormat, format)
+			}
+
+			var w io.Writer = os.Stdout
+			if out != "" {
+				f, err := os.OpenFile(out, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+				if err != nil {
+					return fmt.Errorf("%w:[%v]", ErrCreateFile, err)
+				}
+				defer f.Close()
+				w = f
+			}
+
+			var cutoff time.Time
+			if since > 0 {
+				cutoff = time.Now().Add(-since)
+			}
+
+			write := auditJSONWriter(w)
+			flush := func() error { return nil }
+			if format == "csv" {
+				write, flush = auditCSVWriter(w)
+			}
+
+			if err := auditEach(cmd.Context(), cutoff, write); err != nil {
+				return err
+			}
+
+			return flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "jsonl", "Output format: jsonl or csv")
+	cmd.Flags().StringVar(&out, "out", "", "Output file. Defaults to stdout")
+	cmd.Flags().DurationVar(&since, "since", 0, "Only entries newer than this duration, e.g. 24h")
+
+	return cmd
+}
+
+// auditVerify check the chain through the running node, or in the database when it is stopped.
+// broken tells why the chain is broken
+func auditVerify(ctx context.Context) (int, string, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return 0, "", fmt.Errorf("cannot load config: %w", err)
+	}
+
+	if admin, err := dialAdmin(ctx, cfg); err == nil {
+		var res audit.VerifyResp
+		if err = admin.call(ctx, "AuditService", "Verify", audit.VerifyReq{}, &res); err != nil {
+			return 0, "", err
+		}
+		return res.Checked, res.Broken, nil
+	}
+
+	auditRepo, err := openAuditRepo(cfg)
+	if err != nil {
+		return 0, "", err
+	}
+
+	count, err := auditRepo.Verify(ctx)
+	if errors.Is(err, domain.ErrAuditChainBroken) {
+		return count, err.Error(), nil
+	}
+
+	return count, "", err
+}
+
+// auditEach call fn with the entries newer than since, through the running node or from the database
+func auditEach(ctx context.Context, since time.Time, fn func(domain.AuditEntry) error) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("cannot load config: %w", err)
+	}
+
+	if admin, err := dialAdmin(ctx, cfg); err == nil {
+		req := audit.QueryReq{Limit: 1000}
+		if !since.IsZero() {
+			req.Since = since.Unix()
+		}
+
+		for {
+			var res audit.QueryResp
+			if err = admin.call(ctx, "AuditService", "Query", req, &res); err != nil {
+				return err
+			}
+
+			for _, e := range res.Entries {
+				if err = fn(auditEntryOf(e)); err != nil {
+					return err
+				}
+			}
+
+			if res.Next == 0 {
+				return nil
+			}
+			req.AfterID = res.Next
+		}
+	}
+
+	auditRepo, err := openAuditRepo(cfg)
+	if err != nil {
+		return err
+	}
+
+	return auditRepo.Each(ctx, func(e domain.AuditEntry) error {
+		if e.Time.Before(since) {
+			return nil
+		}
+		return fn(e)
+	})
+}
+
+func openAuditRepo(cfg *config.Config) (*domain.AuditRepository, error) {
+	dbConn, err := setupDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	auditRepo := domain.NewAuditRepository(dbConn)
+	return &auditRepo, nil
+}
+
+func auditEntryOf(e audit.Entry) domain.AuditEntry {
+	return domain.AuditEntry{
+		ID:         e.ID,
+		Time:       time.Unix(0, e.Time).UTC(),
+		PublicKey:  e.PublicKey,
+		UserID:     e.UserID,
+		Service:    e.Service,
+		Action:     e.Action,
+		ParamsHash: e.ParamsHash,
+		Code:       e.Code,
+		RemoteAddr: e.RemoteAddr,
+		Transport:  e.Transport,
+		RequestID:  e.RequestID,
+		PrevHash:   e.PrevHash,
+		Hash:       e.Hash,
+	}
+}
+
+func auditJSONWriter(w io.Writer) func(domain.AuditEntry) error {
+	enc := json.NewEncoder(w)
+	return func(e domain.AuditEntry) error {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("%w:[%v]", ErrWriteFile, err)
+		}
+		return nil
+	}
+}
+
+func auditCSVWriter(w io.Writer) (func(domain.AuditEntry) error, func() error) {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"id", "time", "publicKey", "userId", "service", "action", "paramsHash",
+		"code", "remoteAddr", "transport", "requestId", "prevHash", "hash"})
+
+	write := func(e domain.AuditEntry) error {
+		err := cw.Write([]string{
+			strconv.FormatInt(e.ID, 10),
+			e.Time.Format(time.RFC3339Nano),
+			e.PublicKey,
+			e.UserID,
+			e.Service,
+			e.Action,
+			e.ParamsHash,
+			strconv.FormatUint(uint64(e.Code), 10),
+			e.RemoteAddr,
+			e.Transport,
+			e.RequestID,
+			e.PrevHash,
+			e.Hash,
+		})
+		if err != nil {
+			return fmt.Errorf("%w:[%v]", ErrWriteFile, err)
+		}
+		return nil
+	}
+
+	flush := func() error {
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("%w:[%v]", ErrWriteFile, err)
+		}
+		return nil
+	}
+
+	return write, flush
+}
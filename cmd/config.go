@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"orbital/config"
+	"orbital/internal/system"
+	"orbital/pkg/prompt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "<redacted>"
+
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Show, validate or reload the node config",
+	}
+
+	cmd.AddCommand(newConfigShowCmd())
+	cmd.AddCommand(newConfigValidateCmd())
+	cmd.AddCommand(newConfigReloadCmd())
+
+	return cmd
+}
+
+func newConfigShowCmd() *cobra.Command {
+	var secrets bool
+
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print the effective config: the file, the ORBITAL_* overrides and the defaults",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				return err
+			}
+
+			effective := cfg.WithDefaults()
+			if !secrets {
+				redactConfig(&effective)
+			}
+
+			raw, err := yaml.Marshal(effective)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "# %s\n%s", config.Path(), raw)
+			return err
+		},
+	}
+
+	cmd.Flags().BoolVar(&secrets, "secrets", false, "Print the secret key and the metrics token")
+
+	return cmd
+}
+
+func newConfigValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "validate",
+		Short:         "Check the config file and the ORBITAL_* overrides",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmdHeader("config validate")
+
+			prompt.Info(prompt.NewLine("- File: %s"), config.Path())
+			if names := envOverrides(); len(names) > 0 {
+				prompt.Info(prompt.NewLine("- Env:  %s"), strings.Join(names, ", "))
+			}
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				prompt.Err(prompt.NewLine("- %s"), err.Error())
+				fmt.Println()
+				return ErrInvalidConfig
+			}
+
+			if err = cfg.Validate(); err != nil {
+				for _, line := range strings.Split(err.Error(), "\n") {
+					prompt.Err(prompt.NewLine("- %s"), line)
+				}
+				fmt.Println()
+				return ErrInvalidConfig
+			}
+
+			prompt.OK("%s", prompt.NewLine("- Valid"))
+			fmt.Println()
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newConfigReloadCmd() *cobra.Command {
+	var opts rpcOptions
+
+	cmd := &cobra.Command{
+		Use:   "reload",
+		Short: "Make the running node read its config file again and print the changes",
+		Long: "Make the running node read its config file again, like SIGHUP, and print the changes.\n" +
+			"log, rateLimit, cors and the TLS certificate are applied at once, the other changes need a restart.\n" +
+			"Nothing is applied when the new config is invalid.",
+		Example: "  orbital config reload\n" +
+			"  orbital config reload --node https://node:8080 --sk-file ~/.orbital/sk -o json",
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			defer cancel()
+
+			caller, err := opts.caller(ctx)
+			if err != nil {
+				return rpcError(err)
+			}
+
+			var res system.ReloadResp
+			if err = caller.call(ctx, "SystemService", "Reload", system.ReloadReq{}, &res); err != nil {
+				return rpcError(err)
+			}
+
+			if opts.output != "text" {
+				raw, err := json.Marshal(res)
+				if err != nil {
+					return err
+				}
+				return printBody(cmd.OutOrStdout(), opts.output, raw)
+			}
+
+			printReload(res)
+
+			return nil
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "text", "Output format: text, json or yaml")
+
+	return cmd
+}
+
+func printReload(res system.ReloadResp) {
+	cmdHeader("config reload")
+
+	if len(res.Changes) == 0 {
+		prompt.OK("%s", prompt.NewLine("- No change"))
+		fmt.Println()
+		return
+	}
+
+	for _, change := range res.Changes {
+		if change.Reloadable {
+			prompt.OK(prompt.NewLine("- %s: %s -> %s"), change.Key, change.Old, change.New)
+		} else {
+			prompt.Warn(prompt.NewLine("- %s: %s -> %s (restart needed)"), change.Key, change.Old, change.New)
+		}
+	}
+
+	if res.Restart {
+		prompt.Warn("%s", prompt.NewLine("Some changes apply after a restart"))
+	}
+	fmt.Println()
+}
+
+// envOverrides the ORBITAL_* variables set that override a config value
+func envOverrides() []string {
+	var set []string
+	for _, name := range config.EnvNames() {
+		if _, found := os.LookupEnv(name); found {
+			set = append(set, name)
+		}
+	}
+	return set
+}
+
+// redactConfig hide the secrets of the config before printing it
+func redactConfig(cfg *config.Config) {
+	if cfg.SecretKey != "" {
+		cfg.SecretKey = redacted
+	}
+	if cfg.Metrics != nil && cfg.Metrics.Token != "" {
+		metrics := *cfg.Metrics
+		metrics.Token = redacted
+		cfg.Metrics = &metrics
+	}
+}
@@ -10,4 +10,18 @@ var (
 	ErrReadFile          = errors.New("error reading file")
 	ErrCreateFile        = errors.New("error creating file")
 	ErrWriteFile         = errors.New("error writing file")
+	ErrUnknownFormat     = errors.New("unknown format")
+	ErrNodeNotRunning    = errors.New("node not running")
+	ErrNodeRunning       = errors.New("node is running")
+	ErrAdminDenied       = errors.New("not allowed on the admin socket")
+	ErrAdminReply        = errors.New("bad admin socket reply")
+	ErrNodeUnreachable   = errors.New("node unreachable")
+	ErrBadReply          = errors.New("bad node reply")
+	ErrNodeKeyMismatch   = errors.New("reply not signed by the pinned node key")
+	ErrNodeKeyUnknown    = errors.New("node key not pinned")
+	ErrSecretKeyMissing  = errors.New("secret key missing")
+	ErrBadRoute          = errors.New("route must be <Service>/<Action>")
+	ErrBadSince          = errors.New("since must be topic=seq")
+	ErrNodeNotReady      = errors.New("node not ready")
+	ErrInvalidConfig     = errors.New("invalid config")
 )
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"orbital/pkg/prompt"
+	"orbital/pkg/rpcgen"
+
+	"github.com/spf13/cobra"
+)
+
+func newGenCmd() *cobra.Command {
+
+	genCmd := &cobra.Command{
+		Use:   "gen <schema.yaml>...",
+		Short: "Generate RPC server, wasm client and docs from service schemas",
+		Long: "Generate RPC server registration, typed wasm client and documentation from service schemas.\n" +
+			"Output paths are relative to the schema file. Use it from go:generate:\n\n" +
+			"  //go:generate go run orbital/tools/rpcgen apps.rpc.yaml",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			for _, schemaPath := range args {
+				schema, err := rpcgen.Load(schemaPath)
+				if err != nil {
+					return err
+				}
+
+				files, err := rpcgen.Generate(schema)
+				if err != nil {
+					return err
+				}
+
+				for _, f := range files {
+					prompt.Info("- %s: %s -> %s\n", schema.Service, schemaPath, f.Path)
+					if dryRun {
+						fmt.Println(string(f.Content))
+					}
+				}
+
+				if dryRun {
+					continue
+				}
+
+				if err = rpcgen.Write(files); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	genCmd.Flags().Bool("dry-run", false, "Print generated files instead of writing them")
+
+	return genCmd
+}
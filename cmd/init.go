@@ -46,7 +46,7 @@ func newInitCmd(deps Dependencies) *cobra.Command {
 			prompt.Bold(prompt.ColorGreen, "        OK")
 
 			isReinit := false
-			cfgPath := "/etc/orbital/config.yaml"
+			cfgPath := config.Path()
 			if _, err = os.Stat(cfgPath); !os.IsNotExist(err) {
 				isReinit = true
 			}
@@ -57,6 +57,19 @@ func newInitCmd(deps Dependencies) *cobra.Command {
 				Datapath:  dataPath,
 			}
 
+			// Re-initializing under a running node would swap its config and database.
+			// The overrides apply to the check only, they are not saved
+			runningCfg := orbitalCfg
+			if err = config.ApplyEnv(&runningCfg); err != nil {
+				return err
+			}
+			if existing, err := config.LoadConfig(); err == nil {
+				runningCfg = *existing
+			}
+			if _, err = dialAdmin(cmd.Context(), &runningCfg); err == nil {
+				return fmt.Errorf("%w:[stop it before init]", ErrNodeRunning)
+			}
+
 			prompt.Bold(prompt.ColorYellow, prompt.NewLine("[ Validating private key ]"))
 			if _, err = cryptographer.NewPrivateKeyFromHex(secretKey); err != nil {
 				return ErrInvalidEd25519Key
@@ -87,7 +100,7 @@ func newInitCmd(deps Dependencies) *cobra.Command {
 					return nil
 				}
 
-				backupPath := filepath.Join(filepath.Dir(cfgPath), "config.yaml.old")
+				backupPath := cfgPath + ".old"
 				if err = os.Rename(cfgPath, backupPath); err != nil {
 					// Allow this error to pass to be caught on config file save
 					if !os.IsPermission(err) {
@@ -99,7 +112,7 @@ func newInitCmd(deps Dependencies) *cobra.Command {
 			prompt.Bold(prompt.ColorYellow, prompt.NewLine("[ Creating config file ]"))
 			if err = orbitalCfg.Save(cfgPath); err != nil {
 				if errors.Is(err, config.ErrConfigWrite) {
-					prompt.Warn(prompt.NewLine("Cannot write the file. Use sudo privileges. The config wile will created at: /etc/orbital/config.yaml"))
+					prompt.Warn(prompt.NewLine("Cannot write the file. Use sudo privileges or --config. The config file will be created at: %s"), cfgPath)
 					prompt.Info(prompt.NewLine("If you are not comfortable running Orbital with sudo, create the file manually and copy the following contents between the BEGIN and END to it"))
 					fmt.Println()
 					fmt.Println()
@@ -128,9 +141,9 @@ func newInitCmd(deps Dependencies) *cobra.Command {
 				return err
 			}
 
-			prompt.Info(prompt.NewLine("Config file location: /etc/orbital/config.yaml"))
+			prompt.Info(prompt.NewLine("Config file location: %s"), cfgPath)
 			if forced {
-				prompt.Warn(prompt.NewLine("Old config backup:    /etc/orbital/config.yaml.old"))
+				prompt.Warn(prompt.NewLine("Old config backup:    %s.old"), cfgPath)
 			}
 
 			prompt.Bold(prompt.ColorYellow, prompt.NewLine("[ Migrate database ]"))
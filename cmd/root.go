@@ -4,24 +4,44 @@ import (
 	"fmt"
 	"github.com/spf13/cobra"
 	"io/fs"
+	"orbital/config"
+	"orbital/pkg/buildinfo"
 	"orbital/pkg/prompt"
 )
 
 type Dependencies struct {
-	FS fs.FS
+	FS    fs.FS
+	Build buildinfo.Info
 }
 
 var rootCmd = &cobra.Command{
 	Use:   "orbital",
 	Short: "orbital - container orchestration made simple",
 	Long:  "orbital is a container orchestration system without the bloat",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if configFlag != "" {
+			config.SetPath(configFlag)
+		}
+	},
 }
 
+// configFlag config file set with --config
+var configFlag string
+
 func Execute(deps Dependencies) error {
+	rootCmd.PersistentFlags().StringVar(&configFlag, "config", "", "Config file. Defaults to $"+config.EnvConfig+" or "+config.DefaultPath)
+
 	rootCmd.AddCommand(newInitCmd(deps))
 	rootCmd.AddCommand(newUpdateCmd(deps))
 	rootCmd.AddCommand(newKeygenCmd())
-	rootCmd.AddCommand(newStartCmd())
+	rootCmd.AddCommand(newStartCmd(deps))
+	rootCmd.AddCommand(newGenCmd())
+	rootCmd.AddCommand(newVersionCmd(deps))
+	rootCmd.AddCommand(newAuditCmd())
+	rootCmd.AddCommand(newRpcCmd())
+	rootCmd.AddCommand(newStatusCmd())
+	rootCmd.AddCommand(newTopCmd())
+	rootCmd.AddCommand(newConfigCmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		return err
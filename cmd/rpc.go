@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"orbital/orbital"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func newRpcCmd() *cobra.Command {
+	var (
+		opts rpcOptions
+		data string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "rpc <Service>/<Action>",
+		Short: "Call a node route and print the verified reply body",
+		Long: "Call a node route and print the verified reply body.\n" +
+			"Without --node the local node is called over its admin socket. With --node the request is signed\n" +
+			"with --sk-file and the reply must be signed by the pinned node key (--node-key, or the key\n" +
+			"remembered with --pin in the known nodes file).",
+		Example: "  orbital rpc SystemService/Info\n" +
+			"  orbital rpc AuditService/Query --data '{\"limit\": 10}' -o yaml\n" +
+			"  orbital rpc AppsService/List --node https://node:8080 --sk-file ~/.orbital/sk --pin",
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			service, action, found := strings.Cut(strings.Trim(args[0], "/"), "/")
+			if !found || service == "" || action == "" {
+				return fmt.Errorf("%w:[%s]", ErrBadRoute, args[0])
+			}
+
+			body, err := readRpcData(data, cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			caller, err := opts.caller(ctx)
+			if err != nil {
+				return rpcError(err)
+			}
+
+			var out json.RawMessage
+			if err = caller.call(ctx, service, action, body, &out); err != nil {
+				return rpcError(err)
+			}
+
+			return printBody(cmd.OutOrStdout(), opts.output, out)
+		},
+	}
+
+	opts.addFlags(cmd.PersistentFlags())
+	cmd.PersistentFlags().StringVarP(&opts.output, "output", "o", "json", "Output format: json or yaml")
+	cmd.Flags().StringVarP(&data, "data", "d", "{}", "Request body: JSON, @file or - for stdin")
+
+	cmd.AddCommand(newRpcSubscribeCmd(&opts))
+
+	return cmd
+}
+
+// addFlags register the node and key flags shared by the commands talking to a node
+func (o *rpcOptions) addFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.node, "node", os.Getenv("ORBITAL_NODE"), "Node URL, e.g. https://host:8080. Defaults to $ORBITAL_NODE, empty uses the local node")
+	flags.StringVar(&o.skFile, "sk-file", os.Getenv("ORBITAL_SK_FILE"), "File holding the hex secret key signing the requests. Defaults to $ORBITAL_SK_FILE")
+	flags.StringVar(&o.nodeKey, "node-key", os.Getenv("ORBITAL_NODE_KEY"), "Pinned node public key. Defaults to $ORBITAL_NODE_KEY or the known nodes file")
+	flags.BoolVar(&o.pin, "pin", false, "Trust the node key on first use and remember it")
+	flags.DurationVar(&o.timeout, "timeout", 30*time.Second, "Call timeout")
+}
+
+// readRpcData read the request body from the flag value, a file (@path) or stdin (-)
+func readRpcData(data string, stdin io.Reader) (json.RawMessage, error) {
+	var (
+		raw []byte
+		err error
+	)
+
+	switch {
+	case data == "-":
+		raw, err = io.ReadAll(stdin)
+	case strings.HasPrefix(data, "@"):
+		raw, err = os.ReadFile(strings.TrimPrefix(data, "@"))
+	default:
+		raw = []byte(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w:[%v]", ErrReadFile, err)
+	}
+
+	if !json.Valid(raw) {
+		return nil, fmt.Errorf("%w:[request body is not valid JSON]", ErrReadFile)
+	}
+
+	return raw, nil
+}
+
+// rpcError prefix node errors with their code and type, main prints the error
+func rpcError(err error) error {
+	var e *orbital.Error
+	if errors.As(err, &e) {
+		return fmt.Errorf("%s %s: %w", e.Code.String(), e.Type, err)
+	}
+
+	return err
+}
@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"orbital/config"
+	"orbital/orbital"
+	"orbital/pkg/cryptographer"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// rpcCaller call a route and decode the verified reply body into out.
+// Error replies are returned as *orbital.Error
+type rpcCaller interface {
+	call(ctx context.Context, service, action string, in, out any) error
+	readiness(ctx context.Context) (*orbital.HealthResp, error)
+}
+
+// rpcOptions flags shared by the rpc commands
+type rpcOptions struct {
+	node    string // Node URL. Empty calls the local node
+	skFile  string
+	nodeKey string // Pinned node public key
+	pin     bool   // Trust and remember the node key on first use
+	output  string // json or yaml
+	timeout time.Duration
+}
+
+// nodeClient call a node over HTTP with envelopes signed by the user key.
+// Replies must be signed by the pinned node key
+type nodeClient struct {
+	client  *http.Client
+	node    string
+	signer  cryptographer.Signer
+	nodeKey string
+}
+
+func (c *nodeClient) call(ctx context.Context, service, action string, in, out any) error {
+	msg, err := cryptographer.Encode(c.signer, cryptographer.Metadata{
+		Domain: "rpc",
+		Action: service + "/" + action,
+	}, in)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/rpc/%s/%s", c.node, service, action)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w:[%v]", ErrNodeUnreachable, err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	var reply cryptographer.Message
+	if err = json.NewDecoder(res.Body).Decode(&reply); err != nil {
+		return fmt.Errorf("%w:[status %d, %v]", ErrBadReply, res.StatusCode, err)
+	}
+
+	if err = verifyNodeMessage(reply, c.nodeKey); err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		var errReply orbital.ErrorReply
+		if err = json.Unmarshal(reply.Body, &errReply); err != nil || errReply.Error == nil {
+			return fmt.Errorf("%w:[status %d]", ErrBadReply, res.StatusCode)
+		}
+		return orbital.NewError(errReply.Code, errReply.Error.Type, errReply.Error.Msg).WithDetails(errReply.Error.Details)
+	}
+
+	return json.Unmarshal(reply.Body, out)
+}
+
+// readiness read the readiness report. Probes are unsigned, a 503 still returns the report
+func (c *nodeClient) readiness(ctx context.Context) (*orbital.HealthResp, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.node+"/readyz", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w:[%v]", ErrNodeUnreachable, err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	var health orbital.HealthResp
+	if err = json.NewDecoder(res.Body).Decode(&health); err != nil {
+		return nil, fmt.Errorf("%w:[status %d, %v]", ErrBadReply, res.StatusCode, err)
+	}
+
+	return &health, nil
+}
+
+// verifyNodeMessage check the signature and that the signer is the pinned node key
+func verifyNodeMessage(msg cryptographer.Message, nodeKey string) error {
+	if valid, err := msg.Verify(); err != nil || !valid {
+		return fmt.Errorf("%w:[bad signature]", ErrBadReply)
+	}
+
+	if signer := hex.EncodeToString(msg.PublicKey[:]); signer != nodeKey {
+		return fmt.Errorf("%w:[signed by %s, pinned %s]", ErrNodeKeyMismatch, signer, nodeKey)
+	}
+
+	return nil
+}
+
+// rpcSigner read the user key from --sk-file. Without it the local node key is used, when the config is readable
+func (o rpcOptions) signer() (cryptographer.Signer, error) {
+	if o.skFile == "" {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return nil, fmt.Errorf("%w:[use --sk-file]", ErrSecretKeyMissing)
+		}
+		return cryptographer.NewPrivateKeyFromHex(cfg.SecretKey)
+	}
+
+	raw, err := os.ReadFile(o.skFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w:[%v]", ErrReadFile, err)
+	}
+
+	sk, err := cryptographer.NewPrivateKeyFromHex(strings.TrimSpace(string(raw)))
+	if err != nil {
+		return nil, ErrInvalidEd25519Key
+	}
+
+	return sk, nil
+}
+
+// caller pick the transport: the admin socket of the local node, or the node URL with a signed envelope
+func (o rpcOptions) caller(ctx context.Context) (rpcCaller, error) {
+	if o.node == "" {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return nil, fmt.Errorf("%w:[use --node]", ErrNodeUnreachable)
+		}
+		return dialAdmin(ctx, cfg)
+	}
+
+	signer, err := o.signer()
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: o.timeout}
+
+	nodeKey, err := o.pinnedKey(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nodeClient{
+		client:  client,
+		node:    strings.TrimSuffix(o.node, "/"),
+		signer:  signer,
+		nodeKey: nodeKey,
+	}, nil
+}
+
+// pinnedKey return the node key from --node-key or the known nodes file.
+// With --pin an unknown node key is fetched from SystemService/Info and remembered
+func (o rpcOptions) pinnedKey(ctx context.Context, client *http.Client) (string, error) {
+	if o.nodeKey != "" {
+		return strings.ToLower(o.nodeKey), nil
+	}
+
+	known, err := loadKnownNodes()
+	if err != nil {
+		return "", err
+	}
+
+	if key, found := known[o.node]; found {
+		return key, nil
+	}
+
+	if !o.pin {
+		return "", fmt.Errorf("%w:[%s, use --node-key or --pin]", ErrNodeKeyUnknown, o.node)
+	}
+
+	key, err := fetchNodeKey(ctx, client, o.node)
+	if err != nil {
+		return "", err
+	}
+
+	known[o.node] = key
+	if err = saveKnownNodes(known); err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(os.Stderr, "pinned %s: %s\n", o.node, key)
+
+	return key, nil
+}
+
+// fetchNodeKey read the key signing the error reply of an unsigned call. Only used to pin on first use
+func fetchNodeKey(ctx context.Context, client *http.Client, node string) (string, error) {
+	url := strings.TrimSuffix(node, "/") + "/rpc/SystemService/Info"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader("{}"))
+	if err != nil {
+		return "", err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w:[%v]", ErrNodeUnreachable, err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	var msg cryptographer.Message
+	if err = json.NewDecoder(res.Body).Decode(&msg); err != nil {
+		return "", fmt.Errorf("%w:[%v]", ErrBadReply, err)
+	}
+
+	if valid, err := msg.Verify(); err != nil || !valid {
+		return "", fmt.Errorf("%w:[bad signature]", ErrBadReply)
+	}
+
+	return hex.EncodeToString(msg.PublicKey[:]), nil
+}
+
+// knownNodesPath file mapping node URLs to their pinned key
+func knownNodesPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "orbital", "known_nodes.yaml"), nil
+}
+
+func loadKnownNodes() (map[string]string, error) {
+	path, err := knownNodesPath()
+	if err != nil {
+		return nil, err
+	}
+
+	known := map[string]string{}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return known, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w:[%v]", ErrReadFile, err)
+	}
+
+	if err = yaml.Unmarshal(raw, &known); err != nil {
+		return nil, fmt.Errorf("%w:[%v]", ErrReadFile, err)
+	}
+
+	return known, nil
+}
+
+func saveKnownNodes(known map[string]string) error {
+	path, err := knownNodesPath()
+	if err != nil {
+		return err
+	}
+
+	raw, err := yaml.Marshal(known)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("%w:[%v]", ErrCannotCreateDir, err)
+	}
+
+	if err = os.WriteFile(path, raw, 0600); err != nil {
+		return fmt.Errorf("%w:[%v]", ErrWriteFile, err)
+	}
+
+	return nil
+}
+
+// printBody write a JSON body as indented JSON, or as YAML keeping the key order
+func printBody(w io.Writer, format string, body []byte) error {
+	switch format {
+	case "json":
+		var out bytes.Buffer
+		if err := json.Indent(&out, body, "", "  "); err != nil {
+			return err
+		}
+		out.WriteByte('\n')
+		_, err := w.Write(out.Bytes())
+		return err
+	case "yaml":
+		// JSON is YAML, decoding to a node keeps the order of the keys
+		var node yaml.Node
+		if err := yaml.Unmarshal(body, &node); err != nil {
+			return err
+		}
+		blockStyle(&node)
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(&node); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("%w:[%s]", ErrUnknownFormat, format)
+	}
+}
+
+// blockStyle drop the flow and quoting styles of the nodes decoded from JSON. Tags keep the strings quoted when needed
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"orbital/config"
+	"orbital/orbital"
+	"orbital/pkg/cryptographer"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+// keepAliveInterval how often the subscriber sends system/keepAlivePing. Below the idle timeout of the node
+const keepAliveInterval = 10 * time.Second
+
+// subscriptionEvent line printed for every published message
+type subscriptionEvent struct {
+	Topic string          `json:"topic"`
+	Seq   uint64          `json:"seq,omitempty"`
+	Body  json.RawMessage `json:"body"`
+}
+
+// wsReply the code and error shared by the system replies
+type wsReply struct {
+	Code  orbital.Code           `json:"code"`
+	Error *orbital.ErrorResponse `json:"error,omitempty"`
+}
+
+func newRpcSubscribeCmd(opts *rpcOptions) *cobra.Command {
+	var since map[string]string
+
+	cmd := &cobra.Command{
+		Use:   "subscribe <topic>...",
+		Short: "Subscribe to node topics and print every published message",
+		Long: "Subscribe to node topics and print every published message, one JSON object per line,\n" +
+			"or one YAML document per message with -o yaml. Messages not signed by the node key are rejected.",
+		Example: "  orbital rpc subscribe machine/jobAllData\n" +
+			"  orbital rpc subscribe 'machine/*' --since machine/jobAllData=42 | jq .body",
+		Args:          cobra.MinimumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			seqs := make(map[string]uint64, len(since))
+			for topic, value := range since {
+				seq, err := strconv.ParseUint(value, 10, 64)
+				if err != nil {
+					return fmt.Errorf("%w:[%s=%s]", ErrBadSince, topic, value)
+				}
+				seqs[topic] = seq
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			w := cmd.OutOrStdout()
+			err := subscribe(ctx, *opts, orbital.SubscribeReq{Topics: args, Since: seqs}, wsHandlers{
+				onSubscribed: func(resp orbital.SubscribeResp) {
+					fmt.Fprintf(os.Stderr, "subscribed: %s\n", strings.Join(resp.Topics, ", "))
+					if len(resp.Resync) > 0 {
+						fmt.Fprintf(os.Stderr, "resync: %s\n", strings.Join(resp.Resync, ", "))
+					}
+				},
+				onMessage: func(topic string, msg cryptographer.Message) error {
+					return printEvent(w, opts.output, topic, msg)
+				},
+			})
+			if err != nil && ctx.Err() == nil {
+				return rpcError(err)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringToStringVar(&since, "since", nil, "Last sequence seen per topic, the missed messages are replayed: topic=seq")
+
+	return cmd
+}
+
+// wsHandlers callbacks of a subscription. Messages are handled in order, on the reading goroutine
+type wsHandlers struct {
+	onSubscribed func(resp orbital.SubscribeResp)
+	onMessage    func(topic string, msg cryptographer.Message) error // Published messages, verified
+}
+
+// subscribe dial the node websocket, authenticate, subscribe and hand the published messages until the context ends
+func subscribe(ctx context.Context, opts rpcOptions, req orbital.SubscribeReq, h wsHandlers) error {
+	target, err := opts.wsTarget(ctx)
+	if err != nil {
+		return err
+	}
+	nodeKey, signer := target.nodeKey, target.signer
+
+	url := "ws" + strings.TrimPrefix(target.node, "http") + "/ws"
+	dialCtx, cancel := context.WithTimeout(ctx, opts.timeout)
+	conn, _, err := websocket.Dial(dialCtx, url, &websocket.DialOptions{HTTPClient: target.client})
+	cancel()
+	if err != nil {
+		return fmt.Errorf("%w:[%v]", ErrNodeUnreachable, err)
+	}
+	defer func() { _ = conn.CloseNow() }()
+	conn.SetReadLimit(16 << 20)
+
+	go wsKeepAlive(ctx, conn)
+
+	for {
+		_, raw, err := conn.Read(ctx)
+		if err != nil {
+			return fmt.Errorf("%w:[%v]", ErrNodeUnreachable, err)
+		}
+
+		var msg cryptographer.Message
+		if err = json.Unmarshal(raw, &msg); err != nil {
+			return fmt.Errorf("%w:[%v]", ErrBadReply, err)
+		}
+
+		topic := msg.Metadata.Domain + "/" + msg.Metadata.Action
+
+		// Keepalive messages are unsigned, whoever sends them
+		switch topic {
+		case "system/keepAlivePong":
+			continue
+		case "system/keepAlivePing":
+			_ = wsSend(ctx, conn, keepAliveMessage("keepAlivePong"))
+			continue
+		}
+
+		if err = verifyNodeMessage(msg, nodeKey); err != nil {
+			return err
+		}
+
+		switch topic {
+		case "system/welcome":
+			var welcome orbital.WelcomeMessage
+			if err = json.Unmarshal(msg.Body, &welcome); err != nil {
+				return fmt.Errorf("%w:[%v]", ErrBadReply, err)
+			}
+			auth := orbital.AuthenticateReq{ConnID: welcome.ConnID, ServerTime: welcome.ServerTime}
+			if err = wsSendSigned(ctx, conn, signer, "authenticate", auth); err != nil {
+				return err
+			}
+		case "system/authenticate":
+			if err = wsReplyError(msg.Body); err != nil {
+				return err
+			}
+			if err = wsSendSigned(ctx, conn, signer, "subscribe", req); err != nil {
+				return err
+			}
+		case "system/subscribe":
+			var resp orbital.SubscribeResp
+			if err = json.Unmarshal(msg.Body, &resp); err != nil {
+				return fmt.Errorf("%w:[%v]", ErrBadReply, err)
+			}
+			if err = wsReplyError(msg.Body); err != nil {
+				return err
+			}
+			if h.onSubscribed != nil {
+				h.onSubscribed(resp)
+			}
+		case "system/error":
+			if err = wsReplyError(msg.Body); err != nil {
+				return err
+			}
+		default:
+			if err = h.onMessage(topic, msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// wsEndpoint node to subscribe to
+type wsEndpoint struct {
+	node    string // Base URL
+	nodeKey string // Expected signer of the messages
+	signer  cryptographer.Signer
+	client  *http.Client // Dials the websocket, without timeout
+}
+
+// wsTarget resolve the node URL, its key and the signing key. Without --node the local node is used with its own key
+func (o rpcOptions) wsTarget(ctx context.Context) (*wsEndpoint, error) {
+	signer, err := o.signer()
+	if err != nil {
+		return nil, err
+	}
+
+	if o.node != "" {
+		nodeKey, err := o.pinnedKey(ctx, &http.Client{Timeout: o.timeout})
+		if err != nil {
+			return nil, err
+		}
+		return &wsEndpoint{node: strings.TrimSuffix(o.node, "/"), nodeKey: nodeKey, signer: signer}, nil
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("%w:[use --node]", ErrNodeUnreachable)
+	}
+
+	sk, err := cryptographer.NewPrivateKeyFromHex(cfg.SecretKey)
+	if err != nil {
+		return nil, ErrInvalidEd25519Key
+	}
+
+	return &wsEndpoint{
+		node:    cfg.LocalURL(),
+		nodeKey: sk.PublicKey().ToHex(),
+		signer:  signer,
+		client:  localHTTPClient(cfg, 0),
+	}, nil
+}
+
+// wsKeepAlive send an application level ping, the node closes connections idle for longer than its idle timeout
+func wsKeepAlive(ctx context.Context, conn *websocket.Conn) {
+	tick := time.NewTicker(keepAliveInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			if err := wsSend(ctx, conn, keepAliveMessage("keepAlivePing")); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// keepAliveMessage unsigned system message, like the ones of the web client
+func keepAliveMessage(action string) cryptographer.Message {
+	return cryptographer.Message{
+		Timestamp: cryptographer.Now(),
+		Metadata: cryptographer.Metadata{
+			Domain: "system",
+			Action: action,
+		},
+	}
+}
+
+func wsSendSigned(ctx context.Context, conn *websocket.Conn, signer cryptographer.Signer, action string, body any) error {
+	msg, err := cryptographer.Encode(signer, cryptographer.Metadata{
+		Domain:        "system",
+		Action:        action,
+		CorrelationID: uuid.NewString(),
+	}, body)
+	if err != nil {
+		return err
+	}
+
+	return wsSend(ctx, conn, *msg)
+}
+
+func wsSend(ctx context.Context, conn *websocket.Conn, msg cryptographer.Message) error {
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	if err = conn.Write(ctx, websocket.MessageText, raw); err != nil {
+		return fmt.Errorf("%w:[%v]", ErrNodeUnreachable, err)
+	}
+
+	return nil
+}
+
+// wsReplyError return the error carried by a system reply
+func wsReplyError(body []byte) error {
+	var reply wsReply
+	if err := json.Unmarshal(body, &reply); err != nil {
+		return fmt.Errorf("%w:[%v]", ErrBadReply, err)
+	}
+
+	if reply.Code == orbital.OK {
+		return nil
+	}
+
+	if reply.Error == nil {
+		return orbital.NewError(reply.Code, "ws.error", reply.Code.String())
+	}
+
+	return orbital.NewError(reply.Code, reply.Error.Type, reply.Error.Msg).WithDetails(reply.Error.Details)
+}
+
+// printEvent write a published message as a JSON line, or as a YAML document
+func printEvent(w io.Writer, format, topic string, msg cryptographer.Message) error {
+	seq, _ := strconv.ParseUint(msg.Metadata.Tags["seq"], 10, 64)
+
+	body := msg.Body
+	if len(body) == 0 {
+		body = json.RawMessage("null")
+	}
+
+	raw, err := json.Marshal(subscriptionEvent{Topic: topic, Seq: seq, Body: body})
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		_, err = fmt.Fprintf(w, "%s\n", raw)
+		return err
+	case "yaml":
+		if _, err = io.WriteString(w, "---\n"); err != nil {
+			return err
+		}
+		return printBody(w, format, raw)
+	default:
+		return fmt.Errorf("%w:[%s]", ErrUnknownFormat, format)
+	}
+}
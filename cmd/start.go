@@ -1,17 +1,23 @@
 package cmd
 
 import (
+	"context"
 	"orbital/config"
 	"orbital/domain"
 	"orbital/internal/apps"
+	"orbital/internal/audit"
 	"orbital/internal/auth"
 	"orbital/internal/machine"
+	"orbital/internal/sessions"
 	"orbital/internal/system"
 	"orbital/orbital"
+	"orbital/pkg/agent"
 	"orbital/pkg/db"
 	"orbital/pkg/logger"
 	"orbital/pkg/prompt"
+	"orbital/pkg/tracing"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -20,7 +26,7 @@ var (
 	debug bool
 )
 
-func newStartCmd() *cobra.Command {
+func newStartCmd(deps Dependencies) *cobra.Command {
 
 	startCmd := &cobra.Command{
 		Use:   "start",
@@ -29,18 +35,35 @@ func newStartCmd() *cobra.Command {
 
 			cmdHeader("start")
 
-			var logLvl = logger.LevelError
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				prompt.Err(prompt.NewLine("cannot load config: %s"), err.Error())
+				return err
+			}
+
+			if err = cfg.Validate(); err != nil {
+				prompt.Err(prompt.NewLine("invalid config %s:\n%s"), config.Path(), err.Error())
+				return err
+			}
+
+			logCfg := config.DefaultLog()
+			if cfg.Log != nil {
+				logCfg = *cfg.Log
+			}
+
+			logLvl := logCfg.LoggerLevel()
 			if debug {
 				logLvl = logger.LevelDebug
 			}
 
-			log := logger.New(logLvl, logger.FormatString)
+			log := logger.New(logLvl, logCfg.LoggerFormat())
 
-			cfg, err := config.LoadConfig()
+			shutdownTracing, err := tracing.Setup(cfg.Tracing)
 			if err != nil {
-				prompt.Err(prompt.NewLine("cannot load config: %s"), err.Error())
+				prompt.Err(prompt.NewLine("cannot setup tracing: %s"), err.Error())
 				return err
 			}
+			defer func() { _ = shutdownTracing(context.Background()) }()
 
 			dbConn, err := setupDB(cfg)
 			if err != nil {
@@ -51,54 +74,154 @@ func newStartCmd() *cobra.Command {
 			// Repositories
 			appRepo := domain.NewAppRepository(dbConn)
 			userRepo := domain.NewUserRepository(dbConn)
+			auditRepo := domain.NewAuditRepository(dbConn)
 
 			// Add TCP Server here
 
 			apiSrv := orbital.NewServer(log)
 			wsSrv := orbital.NewWsConn(log)
 
+			rateLimit := config.DefaultRateLimit()
+			if cfg.RateLimit != nil {
+				rateLimit = *cfg.RateLimit
+			}
+
+			limiter := orbital.NewRateLimiter(rateLimit)
+			apiSrv.SetRateLimiter(limiter)
+			wsSrv.SetRateLimiter(limiter)
+
+			// Boot Orbital
+			orbitalCfg := orbital.Config{
+				ApiServer: apiSrv,
+				WsServer:  wsSrv,
+				Addr:      cfg.Addr,
+				Cfg:       cfg,
+				Logger:    log,
+				Limiter:   limiter,
+				Debug:     debug,
+			}
+
+			orbitalNode, err := orbital.New(orbitalCfg)
+			if err != nil {
+				return err
+			}
+
+			replaySize := cfg.Ws.ReplaySize
+			if replaySize <= 0 {
+				replaySize = 256
+			}
+
+			if cfg.Ws.ReplayStore == "sqlite" {
+				wsSrv.SetReplayStore(domain.NewWsReplayRepository(dbConn, replaySize))
+			} else {
+				wsSrv.SetReplayStore(orbital.NewMemoryReplayStore(replaySize))
+			}
+
+			wsSrv.SetTimeouts(cfg.Ws.IdleTimeout, cfg.Ws.CallTimeout)
+			wsSrv.SetQueueConfig(wsQueueConfig(cfg.Ws))
+
+			rbac := auth.NewRBAC(&userRepo)
+			apiSrv.SetAuthorizer(rbac.Authorize)
+			wsSrv.SetAuthorizer(rbac.Authorize)
+
+			auditCfg := config.DefaultAudit()
+			if cfg.Audit != nil {
+				auditCfg = *cfg.Audit
+			}
+
 			// Prepare services
+			auditSvc := audit.NewService(audit.Dependencies{
+				Log:       log,
+				AuditRepo: &auditRepo,
+				UserRepo:  &userRepo,
+				Retention: time.Duration(auditCfg.RetentionDays) * 24 * time.Hour,
+			})
+			defer auditSvc.Close()
+
+			apiSrv.SetAuditor(auditSvc.Record)
+			wsSrv.SetAuditor(auditSvc.Record)
+
 			authSvc := auth.NewService(auth.Dependencies{
 				Log:      log,
 				UserRepo: &userRepo,
 				Ws:       wsSrv,
 			})
 
+			wsSrv.SetAuthenticator(authSvc.Identify)
+
 			appsSvc := apps.NewService(apps.Dependencies{
 				Log:     log,
 				AppRepo: &appRepo,
 			})
 
+			dockerHost := ""
+			if cfg.Docker != nil {
+				dockerHost = cfg.Docker.Host
+			}
+			docker, dockerErr := agent.NewDocker(dockerHost)
+
 			machineSvc := machine.NewService(machine.Dependencies{
-				Log: log,
-				Ws:  wsSrv,
+				Log:    log,
+				Ws:     wsSrv,
+				Signer: orbitalNode.Signer(),
+				Docker: docker, // Nil on error
+			})
+			defer machineSvc.Close()
+
+			sessionsSvc := sessions.NewService(sessions.Dependencies{
+				Log:    log,
+				Ws:     wsSrv,
+				Signer: orbitalNode.Signer(),
 			})
 
 			systemSvc := system.NewService(system.Dependencies{
-				Log: log,
-				Ws:  wsSrv,
+				Log:     log,
+				Api:     apiSrv,
+				Ws:      wsSrv,
+				Signer:  orbitalNode.Signer(),
+				Limiter: limiter,
+				Node:    orbitalNode,
+				Build:   deps.Build,
 			})
 
 			// Register all service to server
+			audit.RegisterAuditServiceServer(apiSrv, wsSrv, auditSvc)
 			auth.RegisterAuthServiceServer(apiSrv, wsSrv, authSvc)
 			apps.RegisterAppsServiceServer(apiSrv, wsSrv, appsSvc)
 			machine.RegisterMachineServiceServer(apiSrv, wsSrv, machineSvc)
+			machine.RegisterMachineTopics(wsSrv)
+			sessions.RegisterSessionsServiceServer(apiSrv, wsSrv, sessionsSvc)
+			sessions.RegisterSessionsTopics(wsSrv)
 			system.RegisterSystemServiceServer(apiSrv, wsSrv, systemSvc)
+			system.RegisterSystemTopics(wsSrv, systemSvc)
 
-			// Boot Orbital
-			orbitalCfg := orbital.Config{
-				ApiServer: apiSrv,
-				WsServer:  wsSrv,
-				Addr:      cfg.Addr,
-				Cfg:       cfg,
-				Logger:    log,
-			}
+			orbitalNode.AddHealthCheck(orbital.HealthCheck{
+				Name:     "db",
+				Liveness: true,
+				Check:    dbConn.Ping,
+			})
+
+			orbitalNode.AddHealthCheck(orbital.HealthCheck{
+				Name: "migrations",
+				Check: func(ctx context.Context) error {
+					return dbConn.CheckMigrations(ctx, deps.FS, "resources/data/migrations")
+				},
+			})
+
+			orbitalNode.AddHealthCheck(orbital.HealthCheck{
+				Name: "docker",
+				Check: func(ctx context.Context) error {
+					if dockerErr != nil {
+						return dockerErr
+					}
+					return docker.Ping(ctx)
+				},
+			})
+
+			log.Info("Orbital build", "version", deps.Build.Version, "branch", deps.Build.Branch, "compile", deps.Build.Compile)
+
+			go orbitalNode.WatchReload(cmd.Context())
 
-			orbitalNode, err := orbital.New(orbitalCfg)
-			if err != nil {
-				return err
-			}
-			
 			if err = orbitalNode.Start(); err != nil {
 				return err
 			}
@@ -111,6 +234,23 @@ func newStartCmd() *cobra.Command {
 	return startCmd
 }
 
+// wsQueueConfig the outbound queue settings, defaults filled for the zero values
+func wsQueueConfig(ws config.WsConfig) orbital.WsQueueConfig {
+	queue := orbital.DefaultWsQueueConfig()
+
+	if ws.QueueSize > 0 {
+		queue.Size = ws.QueueSize
+	}
+	if ws.WriteTimeout > 0 {
+		queue.WriteTimeout = ws.WriteTimeout
+	}
+	if ws.Overflow == "close" {
+		queue.Policy = orbital.OverflowClose
+	}
+
+	return queue
+}
+
 func setupDB(cfg *config.Config) (*db.DB, error) {
 	dbPath := filepath.Join(cfg.OrbitalRootDir(), "data")
 
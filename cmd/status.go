@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"orbital/internal/apps"
+	"orbital/internal/machine"
+	"orbital/internal/sessions"
+	"orbital/internal/system"
+	"orbital/orbital"
+	"orbital/pkg/prompt"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// nodeStatus one-shot summary of a node. A section that cannot be read is reported in Errors
+type nodeStatus struct {
+	Node        string              `json:"node"`
+	Info        *system.InfoResp    `json:"info,omitempty"`
+	Health      *orbital.HealthResp `json:"health,omitempty"`
+	Sessions    int                 `json:"sessions"`
+	UsersOnline []string            `json:"usersOnline"`
+	Apps        []apps.App          `json:"apps"`
+	Containers  []machine.Container `json:"containers"`
+	Errors      map[string]string   `json:"errors,omitempty"`
+}
+
+func newStatusCmd() *cobra.Command {
+	var opts rpcOptions
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Summarize the node health, users online, apps and containers",
+		Long: "Summarize the node health, users online, apps and containers.\n" +
+			"Exits with an error when the node is not ready, so it can be used in scripts.",
+		Example: "  orbital status\n" +
+			"  orbital status --node https://node:8080 --sk-file ~/.orbital/sk -o json",
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(cmd.Context(), opts.timeout)
+			defer cancel()
+
+			caller, err := opts.caller(ctx)
+			if err != nil {
+				return rpcError(err)
+			}
+
+			status := collectStatus(ctx, caller)
+			status.Node = opts.node
+			if status.Node == "" {
+				status.Node = "local"
+			}
+
+			switch opts.output {
+			case "text":
+				printStatus(status)
+			default:
+				raw, err := json.Marshal(status)
+				if err != nil {
+					return err
+				}
+				if err = printBody(cmd.OutOrStdout(), opts.output, raw); err != nil {
+					return err
+				}
+			}
+
+			if status.Health != nil && !status.Health.OK {
+				return ErrNodeNotReady
+			}
+
+			return nil
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "text", "Output format: text, json or yaml")
+
+	return cmd
+}
+
+// collectStatus read every section, keeping going when one fails
+func collectStatus(ctx context.Context, caller rpcCaller) *nodeStatus {
+	status := &nodeStatus{
+		UsersOnline: []string{},
+		Apps:        []apps.App{},
+		Containers:  []machine.Container{},
+		Errors:      map[string]string{},
+	}
+
+	var info system.InfoResp
+	if err := caller.call(ctx, "SystemService", "Info", system.InfoReq{}, &info); err != nil {
+		status.Errors["info"] = err.Error()
+	} else {
+		status.Info = &info
+	}
+
+	if health, err := caller.readiness(ctx); err != nil {
+		status.Errors["health"] = err.Error()
+	} else {
+		status.Health = health
+	}
+
+	var list sessions.ListResp
+	if err := caller.call(ctx, "SessionsService", "List", sessions.ListReq{}, &list); err != nil {
+		status.Errors["sessions"] = err.Error()
+	} else {
+		status.Sessions = len(list.Sessions)
+		users := map[string]struct{}{}
+		for _, s := range list.Sessions {
+			if s.UserID != "" {
+				users[s.UserID] = struct{}{}
+			}
+		}
+		for user := range users {
+			status.UsersOnline = append(status.UsersOnline, user)
+		}
+		sort.Strings(status.UsersOnline)
+	}
+
+	var appList apps.ListResp
+	if err := caller.call(ctx, "AppsService", "List", apps.ListReq{}, &appList); err != nil {
+		status.Errors["apps"] = err.Error()
+	} else if appList.Apps != nil {
+		status.Apps = appList.Apps
+	}
+
+	var containers machine.ContainersResp
+	if err := caller.call(ctx, "MachineService", "Containers", machine.ContainersReq{}, &containers); err != nil {
+		status.Errors["containers"] = err.Error()
+	} else if containers.Containers != nil {
+		status.Containers = containers.Containers
+	}
+
+	if len(status.Errors) == 0 {
+		status.Errors = nil
+	}
+
+	return status
+}
+
+func printStatus(status *nodeStatus) {
+	cmdHeader("status")
+
+	if status.Info != nil {
+		uptime := time.Duration(status.Info.UptimeSeconds) * time.Second
+		prompt.Info(prompt.NewLine("- Node:       %s, %s up %s"), status.Node, status.Info.Build.Version, uptime.String())
+		prompt.Info(prompt.NewLine("- Key:        %s"), status.Info.PublicKey)
+	} else {
+		prompt.Warn(prompt.NewLine("- Node:       %s, %s"), status.Node, status.Errors["info"])
+	}
+
+	switch {
+	case status.Health == nil:
+		prompt.Warn(prompt.NewLine("- Health:     %s"), status.Errors["health"])
+	case status.Health.OK:
+		prompt.OK("%s", prompt.NewLine("- Health:     ready"))
+	default:
+		prompt.Err("%s", prompt.NewLine("- Health:     not ready"))
+	}
+	if status.Health != nil {
+		for _, check := range status.Health.Checks {
+			if check.OK {
+				prompt.OK(prompt.NewLineWithTab("%-12s ok %dms"), check.Name, check.DurationMs)
+			} else {
+				prompt.Err(prompt.NewLineWithTab("%-12s %s"), check.Name, check.Error)
+			}
+		}
+	}
+
+	if msg, failed := status.Errors["sessions"]; failed {
+		prompt.Warn(prompt.NewLine("- Sessions:   %s"), msg)
+	} else {
+		prompt.Info(prompt.NewLine("- Sessions:   %d open, %d users online"), status.Sessions, len(status.UsersOnline))
+	}
+
+	if msg, failed := status.Errors["apps"]; failed {
+		prompt.Warn(prompt.NewLine("- Apps:       %s"), msg)
+	} else {
+		prompt.Info(prompt.NewLine("- Apps:       %d"), len(status.Apps))
+		for _, app := range status.Apps {
+			prompt.Info(prompt.NewLineWithTab("%-24s %s"), app.Name, app.Version)
+		}
+	}
+
+	if msg, failed := status.Errors["containers"]; failed {
+		prompt.Warn(prompt.NewLine("- Containers: %s"), msg)
+	} else {
+		prompt.Info(prompt.NewLine("- Containers: %d"), len(status.Containers))
+		for _, c := range status.Containers {
+			printContainer(c)
+		}
+	}
+
+	fmt.Println()
+}
+
+func printContainer(c machine.Container) {
+	line := prompt.NewLineWithTab("%-24s %-10s %-20s %s")
+	if c.State == "running" {
+		prompt.OK(line, c.Name, c.State, c.Status, c.Image)
+		return
+	}
+	prompt.Warn(line, c.Name, c.State, c.Status, c.Image)
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package cmd
+
+// termSize the window size is only read on unix terminals
+func termSize() (int, int) {
+	return 80, 24
+}
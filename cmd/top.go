@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"orbital/internal/machine"
+	"orbital/orbital"
+	"orbital/pkg/cryptographer"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+const (
+	topEvents = 8 // Container events kept on screen
+
+	// ANSI sequences driving the terminal
+	ansiAltScreen  = "\x1b[?1049h\x1b[?25l"
+	ansiMainScreen = "\x1b[?25h\x1b[?1049l"
+	ansiHome       = "\x1b[H"
+	ansiClearLine  = "\x1b[K"
+	ansiClearDown  = "\x1b[J"
+)
+
+// topStats typed view of the machine/jobAllData body
+type topStats struct {
+	SystemInfo *struct {
+		Info  machine.Info       `json:"info"`
+		CPU   machine.CPUInfo    `json:"cpu"`
+		Disks machine.DiskInfo   `json:"disks"`
+		Mem   machine.MemoryInfo `json:"mem"`
+	} `json:"systemInfo"`
+	Code  orbital.Code           `json:"code"`
+	Error *orbital.ErrorResponse `json:"error,omitempty"`
+}
+
+// topModel state rendered on every update
+type topModel struct {
+	node          string
+	stats         *topStats
+	containers    []machine.Container
+	containersErr string
+	events        []machine.ContainerEvent
+	status        string // Connection state or last error
+}
+
+func newTopCmd() *cobra.Command {
+	var opts rpcOptions
+
+	cmd := &cobra.Command{
+		Use:   "top",
+		Short: "Live view of the node CPU, memory, disks and containers",
+		Long: "Live view of the node CPU, memory, disks and containers, fed by the machine/jobAllData and\n" +
+			"machine/containerEvent topics over /ws. Works on the local node and, with --node, on a remote one.",
+		Example: "  orbital top\n" +
+			"  orbital top --node https://node:8080 --sk-file ~/.orbital/sk",
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			caller, err := opts.caller(ctx)
+			if err != nil {
+				return rpcError(err)
+			}
+
+			model := &topModel{node: opts.node, status: "connecting"}
+			if model.node == "" {
+				model.node = "local"
+			}
+			model.refreshContainers(ctx, caller)
+
+			w := cmd.OutOrStdout()
+			_, _ = io.WriteString(w, ansiAltScreen)
+			defer func() { _, _ = io.WriteString(w, ansiMainScreen) }()
+			model.render(w)
+
+			req := orbital.SubscribeReq{Topics: []string{
+				machine.Domain + "/" + machine.ActionJobAllData,
+				machine.Domain + "/" + machine.ActionContainerEvent,
+			}}
+
+			err = subscribe(ctx, opts, req, wsHandlers{
+				onSubscribed: func(orbital.SubscribeResp) {
+					model.status = "waiting for stats"
+					model.render(w)
+				},
+				onMessage: func(topic string, msg cryptographer.Message) error {
+					model.update(ctx, caller, topic, msg)
+					model.render(w)
+					return nil
+				},
+			})
+			if err != nil && ctx.Err() == nil {
+				return rpcError(err)
+			}
+
+			return nil
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+
+	return cmd
+}
+
+// refreshContainers list the containers again, the events only tell what changed
+func (m *topModel) refreshContainers(ctx context.Context, caller rpcCaller) {
+	var res machine.ContainersResp
+	if err := caller.call(ctx, "MachineService", "Containers", machine.ContainersReq{}, &res); err != nil {
+		m.containersErr = err.Error()
+		return
+	}
+
+	m.containers = res.Containers
+	m.containersErr = ""
+}
+
+func (m *topModel) update(ctx context.Context, caller rpcCaller, topic string, msg cryptographer.Message) {
+	switch topic {
+	case machine.Domain + "/" + machine.ActionJobAllData:
+		var stats topStats
+		if err := json.Unmarshal(msg.Body, &stats); err != nil {
+			m.status = err.Error()
+			return
+		}
+		if stats.Error != nil {
+			m.status = stats.Error.Msg
+		} else {
+			m.status = ""
+		}
+		if stats.SystemInfo != nil {
+			m.stats = &stats
+		}
+	case machine.Domain + "/" + machine.ActionContainerEvent:
+		var event machine.ContainerEvent
+		if err := json.Unmarshal(msg.Body, &event); err != nil {
+			return
+		}
+		m.events = append([]machine.ContainerEvent{event}, m.events...)
+		if len(m.events) > topEvents {
+			m.events = m.events[:topEvents]
+		}
+		m.refreshContainers(ctx, caller)
+	}
+}
+
+// render redraw the whole screen. Lines past the terminal height are dropped
+func (m *topModel) render(w io.Writer) {
+	cols, rows := termSize()
+	barWidth := min(max(cols-40, 10), 50)
+
+	bold := color.New(color.Bold).SprintFunc()
+	var lines []string
+	add := func(format string, args ...any) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+
+	header := "orbital top - " + m.node
+	if m.stats != nil {
+		info := m.stats.SystemInfo.Info
+		uptime := time.Duration(info.Uptime) * time.Second
+		header += fmt.Sprintf("  %s %s %s  up %s", info.Hostname, info.Platform, info.PlatformVersion, uptime.String())
+	}
+	add("%s  %s", bold(header), time.Now().Format(time.TimeOnly))
+	if m.status != "" {
+		add("%s", color.YellowString(m.status))
+	}
+	add("")
+
+	if m.stats != nil {
+		sys := m.stats.SystemInfo
+
+		add("%s", bold(fmt.Sprintf("CPU (%d cores)", sys.CPU.TotalCores)))
+		perRow := max(cols/(barWidth+14), 1)
+		for i := 0; i < len(sys.CPU.CoreLoad); i += perRow {
+			var row []string
+			for j := i; j < min(i+perRow, len(sys.CPU.CoreLoad)); j++ {
+				row = append(row, fmt.Sprintf("%3d %s", j, usageBar(barWidth, sys.CPU.CoreLoad[j])))
+			}
+			add("%s", strings.Join(row, "  "))
+		}
+		add("")
+
+		used := sys.Mem.Total - sys.Mem.Free
+		add("%s", bold("Memory"))
+		add("    %s %s / %s", usageBar(barWidth, percent(used, sys.Mem.Total)), humanBytes(used), humanBytes(sys.Mem.Total))
+		add("")
+
+		add("%s", bold("Disks"))
+		for _, d := range sys.Disks.Disks {
+			used := d.Total - d.Free
+			add("    %-16s %s %s / %s", d.Name, usageBar(barWidth, percent(used, d.Total)), humanBytes(used), humanBytes(d.Total))
+		}
+		add("")
+	}
+
+	add("%s", bold(fmt.Sprintf("Containers (%d)", len(m.containers))))
+	if m.containersErr != "" {
+		add("    %s", color.YellowString(m.containersErr))
+	}
+	for _, c := range m.containers {
+		state := color.YellowString("%-10s", c.State)
+		if c.State == "running" {
+			state = color.GreenString("%-10s", c.State)
+		}
+		add("    %-24s %s %-20s %s", c.Name, state, c.Status, c.Image)
+	}
+	add("")
+
+	if len(m.events) > 0 {
+		add("%s", bold("Events"))
+		for _, e := range m.events {
+			add("    %s %-14s %s (%s)", time.Unix(0, e.Time).Format(time.TimeOnly), e.Action, e.Name, e.Image)
+		}
+	}
+
+	if len(lines) > rows-1 {
+		lines = lines[:rows-1]
+	}
+
+	var screen strings.Builder
+	screen.WriteString(ansiHome)
+	for _, line := range lines {
+		screen.WriteString(line)
+		screen.WriteString(ansiClearLine + "\n")
+	}
+	screen.WriteString(ansiClearDown)
+	_, _ = io.WriteString(w, screen.String())
+}
+
+// usageBar a gauge colored by load, e.g. [||||      ]  40.0%
+func usageBar(width int, pct float64) string {
+	filled := min(max(int(pct/100*float64(width)+0.5), 0), width)
+	bar := strings.Repeat("|", filled) + strings.Repeat(" ", width-filled)
+
+	paint := color.GreenString
+	switch {
+	case pct >= 90:
+		paint = color.RedString
+	case pct >= 70:
+		paint = color.YellowString
+	}
+
+	return fmt.Sprintf("[%s] %5.1f%%", paint("%s", bar), pct)
+}
+
+func percent(part, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
+// humanBytes format a size with binary units: 512B, 1.5K, 3.2G
+func humanBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -8,7 +8,6 @@ import (
 	"orbital/domain"
 	"orbital/pkg/cryptographer"
 	"orbital/pkg/db"
-	"orbital/pkg/files"
 	"orbital/pkg/prompt"
 	"os"
 	"path/filepath"
@@ -35,6 +34,11 @@ func newUpdateCmd(deps Dependencies) *cobra.Command {
 			prompt.Bold(prompt.ColorGreen, "        OK")
 			fmt.Println()
 
+			// The backup and the migrations must not run under a live node using the database
+			if _, err = dialAdmin(cmd.Context(), cfg); err == nil {
+				return fmt.Errorf("%w:[stop it before update, %s answers]", ErrNodeRunning, cfg.AdminSocket())
+			}
+
 			dbPath := filepath.Join(cfg.OrbitalRootDir(), "data")
 			if _, err = os.Stat(dbPath); err != nil {
 				return fmt.Errorf("dbPath [%s] does not exist", dbPath)
@@ -46,27 +50,10 @@ func newUpdateCmd(deps Dependencies) *cobra.Command {
 			}
 
 			prompt.Bold(prompt.ColorYellow, "[ Validating user ]")
-			secretKey, _ := cmd.Flags().GetString("sk")
-			if secretKey == "" {
-				return fmt.Errorf("no secret key provided")
-			}
-
-			sk, err := cryptographer.NewPrivateKeyFromHex(secretKey)
-			if err != nil {
-				return ErrInvalidEd25519Key
-			}
-
-			// TODO: Validate with database too
-			userRepo := domain.NewUserRepository(dbConn)
-			user, err := userRepo.GetByPublicKey(sk.PublicKey().ToHex())
-			if err != nil {
+			if err = validateRootKey(cmd, dbConn); err != nil {
 				return err
 			}
 
-			if user.Access != "root" {
-				return fmt.Errorf("wrong access level for user")
-			}
-
 			prompt.Bold(prompt.ColorGreen, "          OK")
 			fmt.Println()
 
@@ -79,7 +66,7 @@ func newUpdateCmd(deps Dependencies) *cobra.Command {
 			// TODO: Backup db: db_enc_timestamp.db
 			prompt.Bold(prompt.ColorYellow, "[ Backing up database ]")
 
-			if err = files.Backup(filepath.Join(dbPath, "orbital.db")); err != nil {
+			if err = dbConn.Backup(cmd.Context(), filepath.Join(dbPath, "orbital.db.bkp")); err != nil {
 				return err
 			}
 
@@ -110,6 +97,32 @@ func newUpdateCmd(deps Dependencies) *cobra.Command {
 	return cmd
 }
 
+// validateRootKey check the --sk flag is the key of a root user
+func validateRootKey(cmd *cobra.Command, dbConn *db.DB) error {
+	secretKey, _ := cmd.Flags().GetString("sk")
+	if secretKey == "" {
+		return fmt.Errorf("no secret key provided")
+	}
+
+	sk, err := cryptographer.NewPrivateKeyFromHex(secretKey)
+	if err != nil {
+		return ErrInvalidEd25519Key
+	}
+
+	// TODO: Validate with database too
+	userRepo := domain.NewUserRepository(dbConn)
+	user, err := userRepo.GetByPublicKey(cmd.Context(), sk.PublicKey().ToHex())
+	if err != nil {
+		return err
+	}
+
+	if user.Access != "root" {
+		return fmt.Errorf("wrong access level for user")
+	}
+
+	return nil
+}
+
 // updateResources copy resources for new version
 func updateResources(internalDir fs.FS, userStorage string) error {
 	userStorageAbs, err := filepath.Abs(userStorage)
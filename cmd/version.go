@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"orbital/config"
+	"orbital/internal/system"
+	"orbital/orbital"
+	"orbital/pkg/prompt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newVersionCmd(deps Dependencies) *cobra.Command {
+
+	versionCmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print the build metadata and the uptime of the local node",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			cmdHeader("version")
+
+			prompt.Info(prompt.NewLine("- Version: %s"), deps.Build.Version)
+			prompt.Info(prompt.NewLine("- Branch:  %s"), deps.Build.Branch)
+			prompt.Info(prompt.NewLine("- Compile: %s"), deps.Build.Compile)
+			prompt.Info(prompt.NewLine("- Go:      %s"), deps.Build.GoVersion)
+
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				prompt.Warn("%s", prompt.NewLine("- Node:    unknown, cannot load config"))
+				fmt.Println()
+				return nil
+			}
+
+			// The admin socket also reports the node build, which may differ from this binary
+			if admin, err := dialAdmin(cmd.Context(), cfg); err == nil {
+				var info system.InfoResp
+				if err = admin.call(cmd.Context(), "SystemService", "Info", system.InfoReq{}, &info); err == nil {
+					uptime := time.Duration(info.UptimeSeconds) * time.Second
+					prompt.OK(prompt.NewLine("- Node:    %s up %s at %s"), info.Build.Version, uptime.String(), cfg.AdminSocket())
+					fmt.Println()
+					return nil
+				}
+			}
+
+			health, err := fetchHealth(cfg)
+			if err != nil {
+				prompt.Warn(prompt.NewLine("- Node:    not reachable at %s"), cfg.LocalURL())
+				fmt.Println()
+				return nil
+			}
+
+			uptime := time.Duration(health.UptimeSeconds) * time.Second
+			prompt.OK(prompt.NewLine("- Node:    up %s at %s"), uptime.String(), cfg.LocalURL())
+			fmt.Println()
+
+			return nil
+		},
+	}
+
+	return versionCmd
+}
+
+// localHTTPClient client for the local node. Its certificate may not name the loopback, so it is not verified:
+// it only reads the health probes and websocket messages checked against the node key
+func localHTTPClient(cfg *config.Config, timeout time.Duration) *http.Client {
+	client := &http.Client{Timeout: timeout}
+	if cfg.TLS != nil {
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+	return client
+}
+
+// fetchHealth read the liveness report of the local node
+func fetchHealth(cfg *config.Config) (*orbital.HealthResp, error) {
+	client := localHTTPClient(cfg, 2*time.Second)
+
+	res, err := client.Get(cfg.LocalURL() + "/healthz")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	var health orbital.HealthResp
+	if err = json.NewDecoder(res.Body).Decode(&health); err != nil {
+		return nil, err
+	}
+
+	return &health, nil
+}
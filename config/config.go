@@ -1,38 +1,334 @@
 package config
 
 import (
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"net"
+	"orbital/pkg/logger"
+	"orbital/pkg/ratelimit"
+	"orbital/pkg/tracing"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+// DefaultPath config file used without --config or ORBITAL_CONFIG
+const DefaultPath = "/etc/orbital/config.yaml"
+
 type Config struct {
-	SecretKey string `yaml:"secretKey"`
-	Addr      string `yaml:"addr"`
-	Datapath  string `yaml:"dataPath"`
+	SecretKey string          `yaml:"secretKey"`
+	Addr      string          `yaml:"addr"`
+	Datapath  string          `yaml:"dataPath"`
+	Log       *Log            `yaml:"log,omitempty"` // Defaults to DefaultLog
+	TLS       *TLS            `yaml:"tls,omitempty"` // Without it the node serves plain HTTP
+	Ws        WsConfig        `yaml:"ws,omitempty"`
+	RateLimit *RateLimit      `yaml:"rateLimit,omitempty"` // Defaults to DefaultRateLimit
+	Metrics   *Metrics        `yaml:"metrics,omitempty"`   // Without it only loopback can scrape /metrics
+	Tracing   *tracing.Config `yaml:"tracing,omitempty"`   // Span export. Disabled by default
+	Audit     *Audit          `yaml:"audit,omitempty"`     // Defaults to DefaultAudit
+	CORS      *CORS           `yaml:"cors,omitempty"`      // Without it only same origin browsers are allowed
+	Admin     *Admin          `yaml:"admin,omitempty"`     // Defaults to DefaultAdmin
+	Docker    *Docker         `yaml:"docker,omitempty"`    // Without it DOCKER_HOST or the default socket is used
+}
+
+// Log level and format of the node logs
+type Log struct {
+	Level  string `yaml:"level"`  // debug, info, warn or error
+	Format string `yaml:"format"` // text or json
+}
+
+// DefaultLog logging used when the config has no log section
+func DefaultLog() Log {
+	return Log{Level: "error", Format: "text"}
+}
+
+func (l Log) Validate() error {
+	var errs []error
+	if _, found := logLevels[l.Level]; !found {
+		errs = append(errs, fmt.Errorf("%w:[%s]", ErrLogLevel, l.Level))
+	}
+	if l.Format != "text" && l.Format != "json" {
+		errs = append(errs, fmt.Errorf("%w:[%s]", ErrLogFormat, l.Format))
+	}
+	return errors.Join(errs...)
+}
+
+// LoggerLevel the logger level matching the config level
+func (l Log) LoggerLevel() logger.Level {
+	return logLevels[l.Level]
+}
+
+// LoggerFormat the logger format matching the config format
+func (l Log) LoggerFormat() logger.Format {
+	if l.Format == "json" {
+		return logger.FormatJSON
+	}
+	return logger.FormatString
+}
+
+var logLevels = map[string]logger.Level{
+	"debug": logger.LevelDebug,
+	"info":  logger.LevelInfo,
+	"warn":  logger.LevelWarn,
+	"error": logger.LevelError,
+}
+
+// TLS certificate served on addr. Both files are PEM encoded
+type TLS struct {
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+}
+
+func (t TLS) Validate() error {
+	if t.CertFile == "" || t.KeyFile == "" {
+		return ErrTLSIncomplete
+	}
+
+	var errs []error
+	for _, file := range []string{t.CertFile, t.KeyFile} {
+		if _, err := os.Stat(file); err != nil {
+			errs = append(errs, fmt.Errorf("%w:[%v]", ErrTLSFile, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Docker daemon endpoint
+type Docker struct {
+	Host string `yaml:"host"` // unix:///var/run/docker.sock, tcp://host:2376...
+}
+
+func (d Docker) Validate() error {
+	if d.Host == "" {
+		return nil
+	}
+
+	scheme, _, found := strings.Cut(d.Host, "://")
+	if !found {
+		return fmt.Errorf("%w:[%s]", ErrDockerHost, d.Host)
+	}
+
+	switch scheme {
+	case "unix", "tcp", "npipe", "ssh", "http", "https":
+		return nil
+	default:
+		return fmt.Errorf("%w:[%s]", ErrDockerHost, d.Host)
+	}
+}
+
+// Admin local admin API served on a Unix socket. Peers are identified by their credentials, not by a key
+type Admin struct {
+	Socket    string   `yaml:"socket"`              // Socket path. Empty disables it
+	Group     string   `yaml:"group,omitempty"`     // Group owning the socket, so its members can connect
+	AllowUIDs []uint32 `yaml:"allowUids,omitempty"` // Admin users besides root and the node user
+}
+
+// DefaultAdmin socket used when the config has no admin section
+func DefaultAdmin() Admin {
+	return Admin{Socket: "/run/orbital/admin.sock"}
+}
+
+// AdminSocket path of the admin socket, empty when disabled.
+// Without an admin section, nodes not run by root keep it in their data path so they need no /run access
+func (c *Config) AdminSocket() string {
+	if c.Admin != nil {
+		return c.Admin.Socket
+	}
+	if os.Geteuid() != 0 && c.Datapath != "" {
+		return filepath.Join(c.OrbitalRootDir(), "admin.sock")
+	}
+	return DefaultAdmin().Socket
+}
+
+// CORS origins allowed to call /rpc/, /sse and open /ws from a browser. The node origin is always allowed
+type CORS struct {
+	AllowedOrigins   []string `yaml:"allowedOrigins,omitempty"`   // Host patterns: "app.example.com", "*.example.com", "localhost:*" or "*"
+	AllowedHeaders   []string `yaml:"allowedHeaders,omitempty"`   // Preflight request headers. Defaults to DefaultCORS
+	AllowedMethods   []string `yaml:"allowedMethods,omitempty"`   // Preflight methods. Defaults to DefaultCORS
+	AllowCredentials bool     `yaml:"allowCredentials,omitempty"` // Let browsers send cookies and auth headers
+	MaxAge           int      `yaml:"maxAge,omitempty"`           // Seconds a preflight is cached
+}
+
+// DefaultCORS policy used when the config has no cors section: same origin only
+func DefaultCORS() CORS {
+	return CORS{
+		AllowedHeaders: []string{"Content-Type", "Authorization", "Accept-Encoding", "X-Request-Id", "Traceparent"},
+		AllowedMethods: []string{"GET", "POST", "OPTIONS"},
+		MaxAge:         600,
+	}
+}
+
+// Validate check the origin patterns compile
+func (c CORS) Validate() error {
+	for _, pattern := range c.AllowedOrigins {
+		if strings.Contains(pattern, "://") {
+			return fmt.Errorf("%w:[%s, use the host only]", ErrCORSOrigin, pattern)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%w:[%s, %v]", ErrCORSOrigin, pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// Audit retention of the audit log
+type Audit struct {
+	RetentionDays int `yaml:"retentionDays"` // Entries older are pruned. Zero keeps them forever
+}
+
+// DefaultAudit retention used when the config has no audit section
+func DefaultAudit() Audit {
+	return Audit{RetentionDays: 180}
+}
+
+// Metrics access to the /metrics endpoint. A scraper is allowed with the bearer token or from an allowed network
+type Metrics struct {
+	Token    string   `yaml:"token,omitempty"`    // Bearer token presented by the scraper
+	AllowIPs []string `yaml:"allowIps,omitempty"` // IPs or CIDRs allowed without token
+}
+
+// WsConfig websocket settings. Zero values keep the defaults
+type WsConfig struct {
+	ReplayStore  string        `yaml:"replayStore,omitempty"`  // memory (default) or sqlite
+	ReplaySize   int           `yaml:"replaySize,omitempty"`   // Messages kept per topic
+	IdleTimeout  time.Duration `yaml:"idleTimeout,omitempty"`  // Connections silent for longer are closed. Defaults to 30s
+	CallTimeout  time.Duration `yaml:"callTimeout,omitempty"`  // Budget of an RPC call over the socket. Defaults to 30s
+	WriteTimeout time.Duration `yaml:"writeTimeout,omitempty"` // Budget of one write to a client. Defaults to 5s
+	QueueSize    int           `yaml:"queueSize,omitempty"`    // Messages queued per connection. Defaults to 64
+	Overflow     string        `yaml:"overflow,omitempty"`     // drop (default) or close the connection when the queue is full
+}
+
+func (w WsConfig) Validate() error {
+	var errs []error
+
+	if w.ReplayStore != "" && w.ReplayStore != "memory" && w.ReplayStore != "sqlite" {
+		errs = append(errs, fmt.Errorf("%w:[replayStore %s]", ErrWsConfig, w.ReplayStore))
+	}
+	if w.Overflow != "" && w.Overflow != "drop" && w.Overflow != "close" {
+		errs = append(errs, fmt.Errorf("%w:[overflow %s]", ErrWsConfig, w.Overflow))
+	}
+	if w.ReplaySize < 0 || w.QueueSize < 0 {
+		errs = append(errs, fmt.Errorf("%w:[negative size]", ErrWsConfig))
+	}
+	if w.IdleTimeout < 0 || w.CallTimeout < 0 || w.WriteTimeout < 0 {
+		errs = append(errs, fmt.Errorf("%w:[negative timeout]", ErrWsConfig))
+	}
+
+	return errors.Join(errs...)
 }
 
-// Validate config.
-// TODO: Better IP validation
-// TODO: Better DataPath validation
+// Validate check every section and return all the problems found, joined
 func (c *Config) Validate() error {
+	var errs []error
+
 	if len(c.SecretKey) != 64 {
-		return fmt.Errorf("%w:[len: %d]", ErrSecretKeyLength, len(c.SecretKey))
+		errs = append(errs, fmt.Errorf("%w:[len: %d]", ErrSecretKeyLength, len(c.SecretKey)))
+	} else if _, err := hex.DecodeString(c.SecretKey); err != nil {
+		errs = append(errs, fmt.Errorf("%w:[%v]", ErrSecretKeyHex, err))
 	}
 
-	if c.Addr == "" {
-		return validateAddr(c.Addr)
+	if err := validateAddr(c.Addr); err != nil {
+		errs = append(errs, err)
 	}
 
 	if c.Datapath == "" {
-		return fmt.Errorf("%w", ErrDataPathRequired)
+		errs = append(errs, ErrDataPathRequired)
+	} else if !filepath.IsAbs(c.Datapath) {
+		errs = append(errs, fmt.Errorf("%w:[%s]", ErrDataPathRelative, c.Datapath))
 	}
 
-	return nil
+	if c.Log != nil {
+		errs = append(errs, c.Log.Validate())
+	}
+	if c.TLS != nil {
+		errs = append(errs, c.TLS.Validate())
+	}
+	errs = append(errs, c.Ws.Validate())
+	if c.RateLimit != nil {
+		errs = append(errs, c.RateLimit.Validate())
+	}
+	if c.Metrics != nil {
+		errs = append(errs, c.Metrics.Validate())
+	}
+	if c.Tracing != nil && c.Tracing.Exporter != "" && c.Tracing.Exporter != "stdout" {
+		errs = append(errs, fmt.Errorf("%w:[%s]", ErrTracingExporter, c.Tracing.Exporter))
+	}
+	if c.Audit != nil && c.Audit.RetentionDays < 0 {
+		errs = append(errs, fmt.Errorf("%w:[%d]", ErrAuditRetention, c.Audit.RetentionDays))
+	}
+	if c.CORS != nil {
+		errs = append(errs, c.CORS.Validate())
+	}
+	if c.Admin != nil && c.Admin.Socket != "" && !filepath.IsAbs(c.Admin.Socket) {
+		errs = append(errs, fmt.Errorf("%w:[%s]", ErrAdminSocketPath, c.Admin.Socket))
+	}
+	if c.Docker != nil {
+		errs = append(errs, c.Docker.Validate())
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate check the allowed scrapers are IPs or CIDRs
+func (m Metrics) Validate() error {
+	var errs []error
+	for _, allowed := range m.AllowIPs {
+		if net.ParseIP(allowed) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(allowed); err != nil {
+			errs = append(errs, fmt.Errorf("%w:[%s]", ErrMetricsAllowIP, allowed))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RateLimit budgets of the token bucket limiters. A zero rate is unlimited
+type RateLimit struct {
+	IP           ratelimit.Rate            `yaml:"ip"`           // Every request and stream open, per remote IP
+	Key          ratelimit.Rate            `yaml:"key"`          // Calls per public key, once the signature is verified
+	Routes       map[string]ratelimit.Rate `yaml:"routes"`       // Extra budget per route path and remote IP
+	WsMessages   ratelimit.Rate            `yaml:"wsMessages"`   // Messages per websocket connection
+	WsConnsPerIP int                       `yaml:"wsConnsPerIP"` // Open connections per remote IP. Zero is unlimited
+}
+
+// DefaultRateLimit budgets used when the config has no rateLimit section
+func DefaultRateLimit() RateLimit {
+	return RateLimit{
+		IP:  ratelimit.Rate{PerSecond: 20, Burst: 40},
+		Key: ratelimit.Rate{PerSecond: 10, Burst: 20},
+		Routes: map[string]ratelimit.Rate{
+			"/rpc/AuthService/Auth": {PerSecond: 0.2, Burst: 5},
+		},
+		WsMessages:   ratelimit.Rate{PerSecond: 20, Burst: 50},
+		WsConnsPerIP: 20,
+	}
+}
+
+// Validate check no budget is negative
+func (r RateLimit) Validate() error {
+	rates := map[string]ratelimit.Rate{"ip": r.IP, "key": r.Key, "wsMessages": r.WsMessages}
+	for route, rate := range r.Routes {
+		rates[route] = rate
+	}
+
+	var errs []error
+	for name, rate := range rates {
+		if rate.PerSecond < 0 || rate.Burst < 0 {
+			errs = append(errs, fmt.Errorf("%w:[%s]", ErrRateLimitNegative, name))
+		}
+	}
+	if r.WsConnsPerIP < 0 {
+		errs = append(errs, fmt.Errorf("%w:[wsConnsPerIP]", ErrRateLimitNegative))
+	}
+
+	return errors.Join(errs...)
 }
 
 func (c *Config) Save(cfgPath string) error {
@@ -59,13 +355,78 @@ func (c *Config) Save(cfgPath string) error {
 	return nil
 }
 
+// LocalURL base URL a client on this machine reaches the node at. Wildcard hosts are replaced by the loopback
+func (c *Config) LocalURL() string {
+	scheme := "http"
+	if c.TLS != nil {
+		scheme = "https"
+	}
+
+	host, port, err := net.SplitHostPort(c.Addr)
+	if err != nil {
+		return scheme + "://" + c.Addr
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+
+	return scheme + "://" + net.JoinHostPort(host, port)
+}
+
+// WithDefaults copy of the config with the default of every missing section that has one
+func (c Config) WithDefaults() Config {
+	if c.Log == nil {
+		c.Log = ptr(DefaultLog())
+	}
+	if c.RateLimit == nil {
+		c.RateLimit = ptr(DefaultRateLimit())
+	}
+	if c.Audit == nil {
+		c.Audit = ptr(DefaultAudit())
+	}
+	if c.CORS == nil {
+		c.CORS = ptr(DefaultCORS())
+	}
+	if c.Admin == nil {
+		c.Admin = &Admin{Socket: c.AdminSocket()}
+	}
+	return c
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
 func (c *Config) OrbitalRootDir() string {
 	return filepath.Join(c.Datapath, "orbital")
 }
 
+// path set with SetPath, from the --config flag
+var configPath string
+
+// SetPath use the config file at p instead of ORBITAL_CONFIG or DefaultPath
+func SetPath(p string) {
+	configPath = p
+}
+
+// Path of the config file: SetPath, then ORBITAL_CONFIG, then DefaultPath
+func Path() string {
+	if configPath != "" {
+		return configPath
+	}
+	if p := os.Getenv(EnvConfig); p != "" {
+		return p
+	}
+	return DefaultPath
+}
+
+// LoadConfig read the config file at Path and apply the ORBITAL_* environment overrides
 func LoadConfig() (*Config, error) {
+	return Load(Path())
+}
 
-	cfgPath := filepath.Join("/etc/orbital/config.yaml")
+// Load read the config file and apply the ORBITAL_* environment overrides
+func Load(cfgPath string) (*Config, error) {
 	cfgBytes, err := os.ReadFile(cfgPath)
 	if err != nil {
 		return nil, fmt.Errorf("%w:[%s]", ErrConfigRead, err.Error())
@@ -76,6 +437,10 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("%w:[%s]", ErrConfigRead, err.Error())
 	}
 
+	if err = ApplyEnv(&cfg); err != nil {
+		return nil, err
+	}
+
 	return &cfg, nil
 }
 
@@ -111,7 +476,8 @@ func validateAddr(addr string) error {
 		return fmt.Errorf("%w:[%v]", ErrAddrInvalidIP, err)
 	}
 
-	if net.ParseIP(host) == nil {
+	// An empty host listens on every interface
+	if host != "" && net.ParseIP(host) == nil {
 		return fmt.Errorf("%w:[%s]", ErrAddrInvalidIP, host)
 	}
 
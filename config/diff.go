@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Change one config value that differs between two configs
+type Change struct {
+	Key        string `json:"key" yaml:"key"` // yaml path, e.g. rateLimit.ip.perSecond
+	Old        string `json:"old" yaml:"old"`
+	New        string `json:"new" yaml:"new"`
+	Reloadable bool   `json:"reloadable" yaml:"reloadable"` // Applied by a running node, otherwise it needs a restart
+}
+
+// reloadableKeys yaml paths a running node applies on reload, with everything below them
+var reloadableKeys = []string{"log", "rateLimit", "cors", "tls.certFile", "tls.keyFile"}
+
+// secretKeys values never shown in a diff
+var secretKeys = map[string]bool{"secretKey": true, "metrics.token": true}
+
+// Diff list the values that differ between two configs, defaults filled, sorted by key.
+// Secrets are redacted. Turning TLS on or off changes the listener so it is never reloadable
+func Diff(from, to *Config) ([]Change, error) {
+	fromValues, err := flatten(from.WithDefaults())
+	if err != nil {
+		return nil, err
+	}
+	toValues, err := flatten(to.WithDefaults())
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]struct{}, len(fromValues)+len(toValues))
+	for key := range fromValues {
+		keys[key] = struct{}{}
+	}
+	for key := range toValues {
+		keys[key] = struct{}{}
+	}
+
+	tlsToggled := (from.TLS == nil) != (to.TLS == nil)
+
+	var changes []Change
+	for key := range keys {
+		oldValue, newValue := fromValues[key], toValues[key]
+		if oldValue == newValue {
+			continue
+		}
+
+		if secretKeys[key] {
+			oldValue, newValue = redactedValue(oldValue), redactedValue(newValue)
+		}
+
+		changes = append(changes, Change{
+			Key:        key,
+			Old:        oldValue,
+			New:        newValue,
+			Reloadable: isReloadable(key) && !(tlsToggled && strings.HasPrefix(key, "tls.")),
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+
+	return changes, nil
+}
+
+func isReloadable(key string) bool {
+	for _, prefix := range reloadableKeys {
+		if key == prefix || strings.HasPrefix(key, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func redactedValue(v string) string {
+	if v == "" {
+		return ""
+	}
+	return "<redacted>"
+}
+
+// flatten the yaml form of the config into leaf values keyed by their dotted path
+func flatten(cfg Config) (map[string]string, error) {
+	raw, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree map[string]any
+	if err = yaml.Unmarshal(raw, &tree); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	flattenInto(values, "", tree)
+
+	return values, nil
+}
+
+func flattenInto(values map[string]string, prefix string, node any) {
+	m, ok := node.(map[string]any)
+	if !ok {
+		values[prefix] = fmt.Sprint(node)
+		return
+	}
+
+	for key, child := range m {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		flattenInto(values, key, child)
+	}
+}
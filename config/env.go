@@ -0,0 +1,197 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// EnvConfig environment variable holding the config file path
+	EnvConfig = "ORBITAL_CONFIG"
+
+	// EnvPrefix of the variables overriding config values. The name follows the yaml path:
+	// ws.idleTimeout is ORBITAL_WS_IDLE_TIMEOUT, rateLimit.ip.perSecond is ORBITAL_RATE_LIMIT_IP_PER_SECOND
+	EnvPrefix = "ORBITAL"
+)
+
+// sectionDefaults values a missing section starts from when a variable sets one of its fields
+var sectionDefaults = map[reflect.Type]func() any{
+	reflect.TypeOf(Log{}):       func() any { return DefaultLog() },
+	reflect.TypeOf(RateLimit{}): func() any { return DefaultRateLimit() },
+	reflect.TypeOf(Audit{}):     func() any { return DefaultAudit() },
+	reflect.TypeOf(CORS{}):      func() any { return DefaultCORS() },
+	reflect.TypeOf(Admin{}):     func() any { return DefaultAdmin() },
+}
+
+// ApplyEnv override the config values with the ORBITAL_* environment variables.
+// Lists are comma separated, maps cannot be overridden
+func ApplyEnv(cfg *Config) error {
+	return applyEnv(reflect.ValueOf(cfg).Elem(), EnvPrefix)
+}
+
+// EnvNames list the variables that can override the config, sorted as the fields
+func EnvNames() []string {
+	var names []string
+	walkEnvNames(reflect.TypeOf(Config{}), EnvPrefix, &names)
+	return names
+}
+
+func applyEnv(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		name, ok := envName(prefix, field)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case field.Type.Kind() == reflect.Pointer && field.Type.Elem().Kind() == reflect.Struct:
+			if !envHasPrefix(name + "_") {
+				continue
+			}
+			if value.IsNil() {
+				section := reflect.New(field.Type.Elem())
+				if def, found := sectionDefaults[field.Type.Elem()]; found {
+					section.Elem().Set(reflect.ValueOf(def()))
+				}
+				value.Set(section)
+			}
+			if err := applyEnv(value.Elem(), name); err != nil {
+				return err
+			}
+		case field.Type.Kind() == reflect.Struct:
+			if err := applyEnv(value, name); err != nil {
+				return err
+			}
+		default:
+			raw, found := os.LookupEnv(name)
+			if !found {
+				continue
+			}
+			if err := setEnvValue(value, raw); err != nil {
+				return fmt.Errorf("%w:[%s: %v]", ErrEnvOverride, name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func walkEnvNames(t reflect.Type, prefix string, names *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := envName(prefix, field)
+		if !ok {
+			continue
+		}
+
+		ft := field.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		switch ft.Kind() {
+		case reflect.Struct:
+			walkEnvNames(ft, name, names)
+		case reflect.Map:
+		default:
+			*names = append(*names, name)
+		}
+	}
+}
+
+// envName variable name of the field from its yaml key, false for the fields not in the file
+func envName(prefix string, field reflect.StructField) (string, bool) {
+	key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if key == "" || key == "-" || !field.IsExported() {
+		return "", false
+	}
+	return prefix + "_" + upperSnake(key), true
+}
+
+// upperSnake turn a camelCase key into UPPER_SNAKE: dataPath is DATA_PATH, wsConnsPerIP is WS_CONNS_PER_IP
+func upperSnake(key string) string {
+	var b strings.Builder
+	runes := []rune(key)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) ||
+			(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+func envHasPrefix(prefix string) bool {
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func setEnvValue(v reflect.Value, raw string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var parts []string
+		if raw != "" {
+			parts = strings.Split(raw, ",")
+		}
+		list := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setEnvValue(list.Index(i), strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+		v.Set(list)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
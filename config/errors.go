@@ -16,4 +16,21 @@ var (
 	ErrAddrInvalidIPv6 = errors.New("invalid ipv6 address")
 	ErrAddrPort        = errors.New("invalid port")
 	ErrAddrPortNaN     = errors.New("port is not a number")
+
+	ErrCORSOrigin = errors.New("invalid cors origin pattern")
+
+	ErrSecretKeyHex      = errors.New("secret key must be hex encoded")
+	ErrDataPathRelative  = errors.New("data path must be absolute")
+	ErrLogLevel          = errors.New("log level must be debug, info, warn or error")
+	ErrLogFormat         = errors.New("log format must be text or json")
+	ErrTLSIncomplete     = errors.New("tls needs certFile and keyFile")
+	ErrTLSFile           = errors.New("cannot read tls file")
+	ErrWsConfig          = errors.New("invalid ws config")
+	ErrRateLimitNegative = errors.New("rate limit cannot be negative")
+	ErrMetricsAllowIP    = errors.New("metrics allowIps entry is not an ip or cidr")
+	ErrTracingExporter   = errors.New("tracing exporter must be stdout or empty")
+	ErrAuditRetention    = errors.New("audit retention cannot be negative")
+	ErrAdminSocketPath   = errors.New("admin socket path must be absolute")
+	ErrDockerHost        = errors.New("docker host must be a unix://, tcp://, npipe:// or ssh:// url")
+	ErrEnvOverride       = errors.New("invalid environment override")
 )
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	database "orbital/pkg/db"
@@ -108,12 +109,12 @@ func (repo AppRepository) Find() (Apps, error) {
 	return apps, nil
 }
 
-func (repo AppRepository) FindOnlyStandalone() (Apps, error) {
+func (repo AppRepository) FindOnlyStandalone(ctx context.Context) (Apps, error) {
 	query := `SELECT id, name, version, description, icon, namespace, owner_key, owner_url, labels, parent_id, is_external, is_enabled, created_at, updated_at, deleted_at 
 				FROM applications 
 				WHERE parent_id IS NULL AND (deleted_at IS NULL OR deleted_at = '') AND is_enabled = true`
 
-	rows, err := repo.db.Client().Query(query)
+	rows, err := repo.db.Client().QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query users: %w", err)
 	}
@@ -143,12 +144,12 @@ func (repo AppRepository) FindOnlyStandalone() (Apps, error) {
 
 }
 
-func (repo AppRepository) FindByParentID(parentID string) (Apps, error) {
+func (repo AppRepository) FindByParentID(ctx context.Context, parentID string) (Apps, error) {
 	query := `SELECT id, name, version, description, icon, namespace, owner_key, owner_url, labels, parent_id, is_external, is_enabled, created_at, updated_at, deleted_at 
 				FROM applications 
 				WHERE parent_id = ? AND (deleted_at IS NULL OR deleted_at = '') AND is_enabled = true`
 
-	rows, err := repo.db.Client().Query(query, parentID)
+	rows, err := repo.db.Client().QueryContext(ctx, query, parentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query users: %w", err)
 	}
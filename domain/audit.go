@@ -0,0 +1,318 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	database "orbital/pkg/db"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrAuditChainBroken an entry does not match its hash or does not follow the previous one
+var ErrAuditChainBroken = errors.New("audit chain broken")
+
+// AuditEntry one recorded action. Hash chains it to the previous entry
+type AuditEntry struct {
+	ID         int64     `json:"id"`
+	Time       time.Time `json:"time"`
+	PublicKey  string    `json:"publicKey"`
+	UserID     string    `json:"userId"`
+	Service    string    `json:"service"`
+	Action     string    `json:"action"`
+	ParamsHash string    `json:"paramsHash"`
+	Code       uint32    `json:"code"`
+	RemoteAddr string    `json:"remoteAddr"`
+	Transport  string    `json:"transport"`
+	RequestID  string    `json:"requestId"`
+	PrevHash   string    `json:"prevHash"`
+	Hash       string    `json:"hash"`
+}
+
+// ComputeHash hash the entry fields and the previous hash. The id is not part of it
+func (e AuditEntry) ComputeHash() string {
+	fields := []string{
+		e.PrevHash,
+		strconv.FormatInt(e.Time.UnixNano(), 10),
+		e.PublicKey,
+		e.UserID,
+		e.Service,
+		e.Action,
+		e.ParamsHash,
+		strconv.FormatUint(uint64(e.Code), 10),
+		e.RemoteAddr,
+		e.Transport,
+		e.RequestID,
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x1f")))
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditFilter narrow a query. Zero values are ignored
+type AuditFilter struct {
+	PublicKey string
+	UserID    string
+	Service   string
+	Action    string
+	Code      *uint32
+	Since     time.Time
+	Until     time.Time
+	AfterID   int64 // Pagination cursor
+	Limit     int
+}
+
+// AuditRepository append only, hash chained log of the actions made on the node
+type AuditRepository struct {
+	db *database.DB
+}
+
+func NewAuditRepository(db *database.DB) AuditRepository {
+	return AuditRepository{db: db}
+}
+
+// Append chain the entry to the last one and store it. Concurrent appends are serialized by SQLite
+func (repo AuditRepository) Append(ctx context.Context, e AuditEntry) (AuditEntry, error) {
+	tx, err := repo.db.Client().BeginTx(ctx, nil)
+	if err != nil {
+		return e, fmt.Errorf("failed to begin audit append: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	prev, err := lastAuditHash(ctx, tx)
+	if err != nil {
+		return e, err
+	}
+
+	e.PrevHash = prev
+	e.Hash = e.ComputeHash()
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO audit_log
+		(ts, public_key, user_id, service, action, params_hash, code, remote_addr, transport, request_id, prev_hash, hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.Time.UnixNano(), e.PublicKey, e.UserID, e.Service, e.Action, e.ParamsHash, e.Code,
+		e.RemoteAddr, e.Transport, e.RequestID, e.PrevHash, e.Hash)
+	if err != nil {
+		return e, fmt.Errorf("failed to append audit entry: %w", err)
+	}
+
+	if e.ID, err = res.LastInsertId(); err != nil {
+		return e, fmt.Errorf("failed to read audit entry id: %w", err)
+	}
+
+	return e, tx.Commit()
+}
+
+// lastAuditHash hash of the newest entry, or of the anchor once the log was pruned empty
+func lastAuditHash(ctx context.Context, tx *sql.Tx) (string, error) {
+	var hash string
+	err := tx.QueryRowContext(ctx, `SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1`).Scan(&hash)
+	if err == nil {
+		return hash, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("failed to read last audit hash: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `SELECT last_hash FROM audit_anchor WHERE singleton = 1`).Scan(&hash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("failed to read audit anchor: %w", err)
+	}
+
+	return hash, nil
+}
+
+// Query entries matching the filter, oldest first
+func (repo AuditRepository) Query(ctx context.Context, f AuditFilter) ([]AuditEntry, error) {
+	var (
+		where []string
+		args  []any
+	)
+
+	add := func(clause string, arg any) {
+		where = append(where, clause)
+		args = append(args, arg)
+	}
+
+	if f.PublicKey != "" {
+		add("public_key = ?", f.PublicKey)
+	}
+	if f.UserID != "" {
+		add("user_id = ?", f.UserID)
+	}
+	if f.Service != "" {
+		add("service = ?", f.Service)
+	}
+	if f.Action != "" {
+		add("action = ?", f.Action)
+	}
+	if f.Code != nil {
+		add("code = ?", *f.Code)
+	}
+	if !f.Since.IsZero() {
+		add("ts >= ?", f.Since.UnixNano())
+	}
+	if !f.Until.IsZero() {
+		add("ts < ?", f.Until.UnixNano())
+	}
+	if f.AfterID > 0 {
+		add("id > ?", f.AfterID)
+	}
+
+	query := `SELECT id, ts, public_key, user_id, service, action, params_hash, code, remote_addr, transport, request_id, prev_hash, hash
+		FROM audit_log`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id"
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+
+	rows, err := repo.db.Client().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []AuditEntry
+	for rows.Next() {
+		e, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Each call fn with every entry, oldest first. Stops at the first error
+func (repo AuditRepository) Each(ctx context.Context, fn func(AuditEntry) error) error {
+	rows, err := repo.db.Client().QueryContext(ctx, `SELECT id, ts, public_key, user_id, service, action, params_hash, code, remote_addr, transport, request_id, prev_hash, hash
+		FROM audit_log ORDER BY id`)
+	if err != nil {
+		return fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := scanAuditEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err = fn(e); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return nil
+}
+
+// Verify walk the chain from the anchor. Returns the number of checked entries.
+// The error wraps ErrAuditChainBroken with the id of the first bad entry
+func (repo AuditRepository) Verify(ctx context.Context) (int, error) {
+	var (
+		anchorID int64
+		prev     string
+	)
+
+	err := repo.db.Client().QueryRowContext(ctx, `SELECT last_id, last_hash FROM audit_anchor WHERE singleton = 1`).Scan(&anchorID, &prev)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("failed to read audit anchor: %w", err)
+	}
+
+	count := 0
+	lastID := anchorID
+	err = repo.Each(ctx, func(e AuditEntry) error {
+		switch {
+		case e.ID <= lastID:
+			return fmt.Errorf("%w:[id: %d, entry before the anchor]", ErrAuditChainBroken, e.ID)
+		case count == 0 && anchorID > 0 && e.ID != anchorID+1:
+			return fmt.Errorf("%w:[id: %d, entries missing after the anchor %d]", ErrAuditChainBroken, e.ID, anchorID)
+		case e.PrevHash != prev:
+			return fmt.Errorf("%w:[id: %d, previous hash mismatch]", ErrAuditChainBroken, e.ID)
+		case e.ComputeHash() != e.Hash:
+			return fmt.Errorf("%w:[id: %d, content does not match its hash]", ErrAuditChainBroken, e.ID)
+		}
+
+		prev = e.Hash
+		lastID = e.ID
+		count++
+		return nil
+	})
+
+	return count, err
+}
+
+// Prune delete the entries older than before and move the anchor to the last deleted one.
+// Returns the number of deleted entries
+func (repo AuditRepository) Prune(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := repo.db.Client().BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin audit prune: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var (
+		lastID   int64
+		lastHash string
+	)
+	err = tx.QueryRowContext(ctx, `SELECT id, hash FROM audit_log WHERE ts < ? ORDER BY id DESC LIMIT 1`, before.UnixNano()).
+		Scan(&lastID, &lastHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to find prunable audit entries: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM audit_log WHERE id <= ?`, lastID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune audit log: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO audit_anchor (singleton, last_id, last_hash) VALUES (1, ?, ?)
+		ON CONFLICT (singleton) DO UPDATE SET last_id = excluded.last_id, last_hash = excluded.last_hash`, lastID, lastHash)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move audit anchor: %w", err)
+	}
+
+	deleted, _ := res.RowsAffected()
+
+	return deleted, tx.Commit()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAuditEntry(row rowScanner) (AuditEntry, error) {
+	var (
+		e  AuditEntry
+		ts int64
+	)
+
+	err := row.Scan(&e.ID, &ts, &e.PublicKey, &e.UserID, &e.Service, &e.Action, &e.ParamsHash, &e.Code,
+		&e.RemoteAddr, &e.Transport, &e.RequestID, &e.PrevHash, &e.Hash)
+	if err != nil {
+		return e, fmt.Errorf("failed to scan audit row: %w", err)
+	}
+
+	e.Time = time.Unix(0, ts).UTC()
+
+	return e, nil
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	database "orbital/pkg/db"
@@ -62,9 +63,9 @@ func (repo UserRepository) GetByID(id string) (*User, error) {
 	return &user, nil
 }
 
-func (repo UserRepository) GetByPublicKey(pubKey string) (*User, error) {
+func (repo UserRepository) GetByPublicKey(ctx context.Context, pubKey string) (*User, error) {
 	query := `SELECT id, name, pubkey, access FROM users WHERE pubkey = ?`
-	row := repo.db.Client().QueryRow(query, pubKey)
+	row := repo.db.Client().QueryRowContext(ctx, query, pubKey)
 
 	var userR usersRow
 	if err := row.Scan(&userR.ID, &userR.Name, &userR.PubKey, &userR.Access); err != nil {
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"fmt"
+	database "orbital/pkg/db"
+)
+
+// WsReplayRepository persist the messages published on websocket topics so they survive a restart.
+// Only the last size messages of each topic are kept
+type WsReplayRepository struct {
+	db   *database.DB
+	size int
+}
+
+func NewWsReplayRepository(db *database.DB, size int) WsReplayRepository {
+	return WsReplayRepository{db: db, size: size}
+}
+
+func (repo WsReplayRepository) Append(topic string, seq uint64, message []byte) error {
+	tx, err := repo.db.Client().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin replay append: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err = tx.Exec(`INSERT INTO ws_replay (topic, seq, message) VALUES (?, ?, ?)`, topic, seq, message); err != nil {
+		return fmt.Errorf("failed to append replay message: %w", err)
+	}
+
+	if _, err = tx.Exec(`DELETE FROM ws_replay WHERE topic = ? AND seq <= ?`, topic, int64(seq)-int64(repo.size)); err != nil {
+		return fmt.Errorf("failed to truncate replay log: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (repo WsReplayRepository) Since(topic string, seq uint64) ([][]byte, bool, error) {
+	var first, last uint64
+	err := repo.db.Client().
+		QueryRow(`SELECT COALESCE(MIN(seq), 0), COALESCE(MAX(seq), 0) FROM ws_replay WHERE topic = ?`, topic).
+		Scan(&first, &last)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read replay bounds: %w", err)
+	}
+
+	if last == 0 {
+		return nil, seq == 0, nil
+	}
+
+	if seq > last || first > seq+1 {
+		return nil, false, nil
+	}
+
+	rows, err := repo.db.Client().Query(`SELECT message FROM ws_replay WHERE topic = ? AND seq > ? ORDER BY seq`, topic, seq)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to query replay log: %w", err)
+	}
+	defer rows.Close()
+
+	var messages [][]byte
+	for rows.Next() {
+		var message []byte
+		if err = rows.Scan(&message); err != nil {
+			return nil, false, fmt.Errorf("failed to scan replay row: %w", err)
+		}
+
+		messages = append(messages, message)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return messages, true, nil
+}
+
+func (repo WsReplayRepository) Last(topic string) (uint64, error) {
+	var last uint64
+	err := repo.db.Client().QueryRow(`SELECT COALESCE(MAX(seq), 0) FROM ws_replay WHERE topic = ?`, topic).Scan(&last)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read replay sequence: %w", err)
+	}
+
+	return last, nil
+}
@@ -8,10 +8,7 @@ import (
 	"orbital/pkg/logger"
 )
 
-const (
-	Domain     = "apps"
-	ActionList = "list"
-)
+//go:generate go run orbital/tools/rpcgen apps.rpc.yaml
 
 type Dependencies struct {
 	Log     *logger.Logger
@@ -30,14 +27,14 @@ func NewService(deps Dependencies) *Apps {
 	}
 }
 
-func (service *Apps) List(_ context.Context, _ ListReq) (*ListResp, error) {
+func (service *Apps) List(ctx context.Context, _ ListReq) (*ListResp, error) {
 
 	var (
 		dbApps domain.Apps
 		err    error
 	)
 
-	dbApps, err = service.appRepo.FindOnlyStandalone()
+	dbApps, err = service.appRepo.FindOnlyStandalone(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +43,7 @@ func (service *Apps) List(_ context.Context, _ ListReq) (*ListResp, error) {
 	for _, dbApp := range dbApps {
 		var appsTree App
 
-		appsTree, err = service.buildTree(dbApp)
+		appsTree, err = service.buildTree(ctx, dbApp)
 		if err != nil {
 			return nil, err
 		}
@@ -61,8 +58,8 @@ func (service *Apps) List(_ context.Context, _ ListReq) (*ListResp, error) {
 }
 
 // buildTree - recursive apps retrieval
-func (service *Apps) buildTree(app domain.App) (App, error) {
-	children, err := service.appRepo.FindByParentID(app.ID)
+func (service *Apps) buildTree(ctx context.Context, app domain.App) (App, error) {
+	children, err := service.appRepo.FindByParentID(ctx, app.ID)
 	if err != nil {
 		return App{}, fmt.Errorf("error fetching children for %s: %w", app.ID, err)
 	}
@@ -70,7 +67,7 @@ func (service *Apps) buildTree(app domain.App) (App, error) {
 	var childrenList []App
 	for _, child := range children {
 		var childNode App
-		childNode, err = service.buildTree(child)
+		childNode, err = service.buildTree(ctx, child)
 		if err != nil {
 			return App{}, err
 		}
@@ -88,6 +85,7 @@ func (service *Apps) buildTree(app domain.App) (App, error) {
 		OwnerKey:    app.OwnerKey,
 		OwnerURL:    app.OwnerURL,
 		Labels:      app.Labels,
+		IsExternal:  app.IsExternal,
 		Apps:        childrenList,
 	}, nil
 }
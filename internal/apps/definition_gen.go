@@ -0,0 +1,104 @@
+// Code generated by orbital gen. DO NOT EDIT.
+// Source: AppsService schema
+
+package apps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"orbital/internal/auth"
+	"orbital/orbital"
+	"orbital/pkg/cryptographer"
+)
+
+const (
+	Domain     = "apps"
+	ActionList = "list"
+)
+
+// AppsService manages the applications installed on the node.
+type AppsService interface {
+	// List the enabled standalone apps with their children.
+	List(ctx context.Context, req ListReq) (*ListResp, error)
+}
+
+// App struct holder for apps
+type App struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Icon        string   `json:"icon"`
+	Version     string   `json:"version"`
+	Description string   `json:"description"`
+	Namespace   string   `json:"namespace"`
+	OwnerKey    string   `json:"ownerKey"`
+	OwnerURL    string   `json:"ownerUrl"`
+	Labels      []string `json:"labels"`
+	IsExternal  bool     `json:"isExternal"`
+	Apps        []App    `json:"apps"` // If an app it's a suite of apps (just a group basically)
+}
+
+// ListReq filters the listed apps.
+type ListReq struct {
+}
+
+type ListResp struct {
+	Apps  []App                  `json:"apps"`
+	Code  orbital.Code           `json:"code"`
+	Error *orbital.ErrorResponse `json:"error,omitempty"`
+}
+
+type appsServiceServer struct {
+	server  orbital.HTTPService
+	service AppsService
+}
+
+func RegisterAppsServiceServer(server orbital.HTTPService, _ orbital.WsService, service AppsService) {
+	handler := &appsServiceServer{
+		server:  server,
+		service: service,
+	}
+
+	group := server.Group("AppsService",
+		auth.MessageDecode(server),
+		auth.ValidateRole(server),
+	)
+
+	group.Register(orbital.Route{
+		ActionName:  "List",
+		Handler:     handler.handleList,
+		Method:      http.MethodPost,
+		Permission:  "apps:list",
+		Description: "List the enabled standalone apps with their children.",
+		Request:     ListReq{},
+		Response:    ListResp{},
+	})
+}
+
+func (s *appsServiceServer) handleList(w http.ResponseWriter, r *http.Request) {
+	body, ok := r.Context().Value(cryptographer.BodyCtxKey).([]byte)
+	if !ok {
+		s.server.OnError(w, r, orbital.ErrBadPayload)
+		return
+	}
+
+	var req ListReq
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.server.OnError(w, r, fmt.Errorf("%w:[%v]", orbital.ErrUnmarshalPayload, err))
+			return
+		}
+	}
+
+	res, err := s.service.List(r.Context(), req)
+	if err != nil {
+		s.server.OnError(w, r, err)
+		return
+	}
+
+	s.server.Reply(w, r, cryptographer.Metadata{
+		Domain: Domain,
+		Action: ActionList,
+	}, res)
+}
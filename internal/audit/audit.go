@@ -0,0 +1,210 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"orbital/domain"
+	"orbital/orbital"
+	"orbital/pkg/jobber"
+	"orbital/pkg/logger"
+	"sync"
+	"time"
+)
+
+//go:generate go run orbital/tools/rpcgen audit.rpc.yaml
+
+const (
+	queueSize    = 1024
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+type Dependencies struct {
+	Log       *logger.Logger
+	AuditRepo *domain.AuditRepository
+	UserRepo  *domain.UserRepository
+	Retention time.Duration // Entries older are pruned hourly. Zero keeps them forever
+}
+
+// Audit write the records of the servers to the hash chained log.
+// A single writer keeps the chain ordered
+type Audit struct {
+	log       *logger.Logger
+	auditRepo *domain.AuditRepository
+	userRepo  *domain.UserRepository
+	retention time.Duration
+	queue     chan orbital.AuditRecord
+	done      chan struct{}
+	jr        *jobber.Runner
+
+	mu     sync.RWMutex // Held by Record while queueing, taken by Close to close the queue
+	closed bool
+}
+
+func NewService(deps Dependencies) *Audit {
+	service := &Audit{
+		log:       deps.Log,
+		auditRepo: deps.AuditRepo,
+		userRepo:  deps.UserRepo,
+		retention: deps.Retention,
+		queue:     make(chan orbital.AuditRecord, queueSize),
+		done:      make(chan struct{}),
+		jr:        jobber.New(1),
+	}
+
+	go service.writer()
+
+	if service.retention > 0 {
+		go service.prune()
+		service.jr.AddJob(time.Hour, jobber.MaxRunInfinite, service.prune)
+	}
+
+	return service
+}
+
+// Record queue the record for the writer. Blocks when the queue is full so no action goes unrecorded.
+// Handlers still running after Close get their record logged and dropped
+func (service *Audit) Record(ctx context.Context, record orbital.AuditRecord) {
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+
+	if service.closed {
+		service.log.WarnContext(ctx, "audit log closed, record dropped", "service", record.Service, "action", record.Action)
+		return
+	}
+
+	service.queue <- record
+}
+
+// Close flush the queued records and stop the retention job. The writer runs until the queue is
+// closed, so a Record waiting on a full queue is served before Close gets the lock
+func (service *Audit) Close() {
+	service.jr.Shutdown()
+
+	service.mu.Lock()
+	if !service.closed {
+		service.closed = true
+		close(service.queue)
+	}
+	service.mu.Unlock()
+
+	<-service.done
+}
+
+func (service *Audit) writer() {
+	defer close(service.done)
+
+	ctx := context.Background()
+	for record := range service.queue {
+		entry := domain.AuditEntry{
+			Time:       record.Time,
+			PublicKey:  record.PublicKey,
+			UserID:     record.UserID,
+			Service:    record.Service,
+			Action:     record.Action,
+			ParamsHash: record.ParamsHash,
+			Code:       uint32(record.Code),
+			RemoteAddr: record.RemoteAddr,
+			Transport:  record.Transport,
+			RequestID:  record.RequestID,
+		}
+
+		if entry.UserID == "" && entry.PublicKey != "" {
+			if user, err := service.userRepo.GetByPublicKey(ctx, entry.PublicKey); err == nil {
+				entry.UserID = user.ID
+			}
+		}
+
+		if _, err := service.auditRepo.Append(ctx, entry); err != nil {
+			service.log.Error("cannot append audit entry", "service", entry.Service, "action", entry.Action, "requestId", entry.RequestID, "err", err)
+		}
+	}
+}
+
+func (service *Audit) prune() {
+	deleted, err := service.auditRepo.Prune(context.Background(), time.Now().Add(-service.retention))
+	if err != nil {
+		service.log.Error("cannot prune audit log", "err", err)
+		return
+	}
+
+	if deleted > 0 {
+		service.log.Info("audit log pruned", "deleted", deleted)
+	}
+}
+
+func (service *Audit) Query(ctx context.Context, req QueryReq) (*QueryResp, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	limit = min(limit, maxLimit)
+
+	filter := domain.AuditFilter{
+		PublicKey: req.PublicKey,
+		UserID:    req.UserID,
+		Service:   req.Service,
+		Action:    req.Action,
+		Code:      req.Code,
+		AfterID:   req.AfterID,
+		Limit:     limit,
+	}
+	if req.Since > 0 {
+		filter.Since = time.Unix(req.Since, 0)
+	}
+	if req.Until > 0 {
+		filter.Until = time.Unix(req.Until, 0)
+	}
+
+	entries, err := service.auditRepo.Query(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &QueryResp{
+		Code:    orbital.OK,
+		Entries: make([]Entry, 0, len(entries)),
+	}
+
+	for _, e := range entries {
+		res.Entries = append(res.Entries, Entry{
+			ID:         e.ID,
+			Time:       e.Time.UnixNano(),
+			PublicKey:  e.PublicKey,
+			UserID:     e.UserID,
+			Service:    e.Service,
+			Action:     e.Action,
+			ParamsHash: e.ParamsHash,
+			Code:       e.Code,
+			RemoteAddr: e.RemoteAddr,
+			Transport:  e.Transport,
+			RequestID:  e.RequestID,
+			PrevHash:   e.PrevHash,
+			Hash:       e.Hash,
+		})
+	}
+
+	if len(entries) == limit {
+		res.Next = entries[len(entries)-1].ID
+	}
+
+	return res, nil
+}
+
+func (service *Audit) Verify(ctx context.Context, _ VerifyReq) (*VerifyResp, error) {
+	checked, err := service.auditRepo.Verify(ctx)
+	if err != nil && !errors.Is(err, domain.ErrAuditChainBroken) {
+		return nil, err
+	}
+
+	res := &VerifyResp{
+		Code:    orbital.OK,
+		Checked: checked,
+		Valid:   err == nil,
+	}
+	if err != nil {
+		res.Broken = err.Error()
+	}
+
+	return res, nil
+}
@@ -0,0 +1,166 @@
+// Code generated by orbital gen. DO NOT EDIT.
+// Source: AuditService schema
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"orbital/internal/auth"
+	"orbital/orbital"
+	"orbital/pkg/cryptographer"
+)
+
+const (
+	Domain       = "audit"
+	ActionQuery  = "query"
+	ActionVerify = "verify"
+)
+
+// AuditService reads the hash chained log of the actions made on the node.
+type AuditService interface {
+	// Query the audit log, oldest entries first.
+	Query(ctx context.Context, req QueryReq) (*QueryResp, error)
+	// Verify Check the hash chain of the audit log.
+	Verify(ctx context.Context, req VerifyReq) (*VerifyResp, error)
+}
+
+// Entry one recorded action
+type Entry struct {
+	ID         int64  `json:"id"`
+	Time       int64  `json:"time"`      // Unix nanoseconds
+	PublicKey  string `json:"publicKey"` // Verified signer. Empty for public routes
+	UserID     string `json:"userId"`
+	Service    string `json:"service"`
+	Action     string `json:"action"`
+	ParamsHash string `json:"paramsHash"` // SHA-256 of the request body
+	Code       uint32 `json:"code"`       // Reply code
+	RemoteAddr string `json:"remoteAddr"`
+	Transport  string `json:"transport"` // http, batch or ws
+	RequestID  string `json:"requestId"`
+	PrevHash   string `json:"prevHash"`
+	Hash       string `json:"hash"`
+}
+
+type QueryReq struct {
+	PublicKey string  `json:"publicKey,omitempty"`
+	UserID    string  `json:"userId,omitempty"`
+	Service   string  `json:"service,omitempty"`
+	Action    string  `json:"action,omitempty"`
+	Code      *uint32 `json:"code,omitempty"`    // Only entries with this reply code
+	Since     int64   `json:"since,omitempty"`   // Unix seconds, inclusive
+	Until     int64   `json:"until,omitempty"`   // Unix seconds, exclusive
+	AfterID   int64   `json:"afterId,omitempty"` // Cursor: the next value of the previous page
+	Limit     int     `json:"limit,omitempty"`   // Defaults to 100, at most 1000
+}
+
+type QueryResp struct {
+	Entries []Entry                `json:"entries"`
+	Next    int64                  `json:"next"` // Cursor of the next page. Zero on the last page
+	Code    orbital.Code           `json:"code"`
+	Error   *orbital.ErrorResponse `json:"error,omitempty"`
+}
+
+type VerifyReq struct {
+}
+
+type VerifyResp struct {
+	Checked int                    `json:"checked"` // Entries checked before the first broken one
+	Valid   bool                   `json:"valid"`
+	Broken  string                 `json:"broken,omitempty"` // Why the chain is broken
+	Code    orbital.Code           `json:"code"`
+	Error   *orbital.ErrorResponse `json:"error,omitempty"`
+}
+
+type auditServiceServer struct {
+	server  orbital.HTTPService
+	service AuditService
+}
+
+func RegisterAuditServiceServer(server orbital.HTTPService, _ orbital.WsService, service AuditService) {
+	handler := &auditServiceServer{
+		server:  server,
+		service: service,
+	}
+
+	group := server.Group("AuditService",
+		auth.MessageDecode(server),
+		auth.ValidateRole(server),
+	)
+
+	group.Register(orbital.Route{
+		ActionName:  "Query",
+		Handler:     handler.handleQuery,
+		Method:      http.MethodPost,
+		Permission:  "audit:query",
+		Description: "Query the audit log, oldest entries first.",
+		Request:     QueryReq{},
+		Response:    QueryResp{},
+	})
+
+	group.Register(orbital.Route{
+		ActionName:  "Verify",
+		Handler:     handler.handleVerify,
+		Method:      http.MethodPost,
+		Permission:  "audit:verify",
+		Description: "Check the hash chain of the audit log.",
+		Request:     VerifyReq{},
+		Response:    VerifyResp{},
+	})
+}
+
+func (s *auditServiceServer) handleQuery(w http.ResponseWriter, r *http.Request) {
+	body, ok := r.Context().Value(cryptographer.BodyCtxKey).([]byte)
+	if !ok {
+		s.server.OnError(w, r, orbital.ErrBadPayload)
+		return
+	}
+
+	var req QueryReq
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.server.OnError(w, r, fmt.Errorf("%w:[%v]", orbital.ErrUnmarshalPayload, err))
+			return
+		}
+	}
+
+	res, err := s.service.Query(r.Context(), req)
+	if err != nil {
+		s.server.OnError(w, r, err)
+		return
+	}
+
+	s.server.Reply(w, r, cryptographer.Metadata{
+		Domain: Domain,
+		Action: ActionQuery,
+	}, res)
+}
+
+func (s *auditServiceServer) handleVerify(w http.ResponseWriter, r *http.Request) {
+	body, ok := r.Context().Value(cryptographer.BodyCtxKey).([]byte)
+	if !ok {
+		s.server.OnError(w, r, orbital.ErrBadPayload)
+		return
+	}
+
+	var req VerifyReq
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.server.OnError(w, r, fmt.Errorf("%w:[%v]", orbital.ErrUnmarshalPayload, err))
+			return
+		}
+	}
+
+	res, err := s.service.Verify(r.Context(), req)
+	if err != nil {
+		s.server.OnError(w, r, err)
+		return
+	}
+
+	s.server.Reply(w, r, cryptographer.Metadata{
+		Domain: Domain,
+		Action: ActionVerify,
+	}, res)
+}
@@ -2,16 +2,14 @@ package auth
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"orbital/domain"
 	"orbital/orbital"
 	"orbital/pkg/logger"
 )
 
-const (
-	Domain      = "auth"
-	ActionLogin = "login"
-	ActionCheck = "check"
-)
+//go:generate go run orbital/tools/rpcgen auth.rpc.yaml
 
 type Dependencies struct {
 	Log      *logger.Logger
@@ -34,9 +32,10 @@ func NewService(deps Dependencies) *Auth {
 	}
 }
 
-func (service *Auth) Auth(ctx context.Context, req AuthReq) (*AuthResp, error) {
+// Auth return the user holding the key that signed the request
+func (service *Auth) Auth(ctx context.Context, _ AuthReq) (*AuthResp, error) {
 
-	userRepo, err := service.userRepo.GetByPublicKey(req.PublicKey)
+	userRepo, err := service.userRepo.GetByPublicKey(ctx, orbital.SignerKey(ctx))
 	if err != nil {
 		return &AuthResp{
 			Code: orbital.NotFound,
@@ -68,3 +67,20 @@ func (service *Auth) Check(ctx context.Context, req CheckReq) (*CheckResp, error
 	}, nil
 
 }
+
+// Identify resolve the user bound to a websocket connection by the system/authenticate handshake
+func (service *Auth) Identify(ctx context.Context, publicKey string) (*orbital.WsIdentity, error) {
+	user, err := service.userRepo.GetByPublicKey(ctx, publicKey)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, orbital.NewError(orbital.Unauthenticated, "auth.unknownKey", "unknown public key")
+		}
+
+		return nil, err
+	}
+
+	return &orbital.WsIdentity{
+		UserID: user.ID,
+		Role:   user.Access,
+	}, nil
+}
@@ -0,0 +1,143 @@
+// Code generated by orbital gen. DO NOT EDIT.
+// Source: AuthService schema
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"orbital/orbital"
+	"orbital/pkg/cryptographer"
+)
+
+const (
+	Domain      = "auth"
+	ActionAuth  = "login"
+	ActionCheck = "check"
+)
+
+// AuthService resolves the user signing the envelopes.
+type AuthService interface {
+	// Auth resolve the envelope signer to its user.
+	Auth(ctx context.Context, req AuthReq) (*AuthResp, error)
+	// Check the envelope signer is still a valid user.
+	Check(ctx context.Context, req CheckReq) (*CheckResp, error)
+}
+
+type User struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	PublicKey string `json:"publicKey"`
+	Access    string `json:"access"`
+}
+
+// AuthReq is empty, the user is the one holding the envelope key.
+type AuthReq struct {
+}
+
+type AuthResp struct {
+	User  *User                  `json:"user"`
+	Code  orbital.Code           `json:"code"`
+	Error *orbital.ErrorResponse `json:"error,omitempty"`
+}
+
+type CheckReq struct {
+}
+
+type CheckResp struct {
+	Code  orbital.Code           `json:"code"`
+	Error *orbital.ErrorResponse `json:"error,omitempty"`
+}
+
+type authServiceServer struct {
+	server  orbital.HTTPService
+	service AuthService
+}
+
+func RegisterAuthServiceServer(server orbital.HTTPService, _ orbital.WsService, service AuthService) {
+	handler := &authServiceServer{
+		server:  server,
+		service: service,
+	}
+
+	group := server.Group("AuthService",
+		MessageDecode(server),
+		ValidateRole(server),
+	)
+
+	group.Register(orbital.Route{
+		ActionName:  "Auth",
+		Handler:     handler.handleAuth,
+		Method:      http.MethodPost,
+		Permission:  "",
+		Description: "Auth resolve the envelope signer to its user.",
+		Request:     AuthReq{},
+		Response:    AuthResp{},
+	})
+
+	group.Register(orbital.Route{
+		ActionName:  "Check",
+		Handler:     handler.handleCheck,
+		Method:      http.MethodPost,
+		Permission:  "",
+		Description: "Check the envelope signer is still a valid user.",
+		Request:     CheckReq{},
+		Response:    CheckResp{},
+	})
+}
+
+func (s *authServiceServer) handleAuth(w http.ResponseWriter, r *http.Request) {
+	body, ok := r.Context().Value(cryptographer.BodyCtxKey).([]byte)
+	if !ok {
+		s.server.OnError(w, r, orbital.ErrBadPayload)
+		return
+	}
+
+	var req AuthReq
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.server.OnError(w, r, fmt.Errorf("%w:[%v]", orbital.ErrUnmarshalPayload, err))
+			return
+		}
+	}
+
+	res, err := s.service.Auth(r.Context(), req)
+	if err != nil {
+		s.server.OnError(w, r, err)
+		return
+	}
+
+	s.server.Reply(w, r, cryptographer.Metadata{
+		Domain: Domain,
+		Action: ActionAuth,
+	}, res)
+}
+
+func (s *authServiceServer) handleCheck(w http.ResponseWriter, r *http.Request) {
+	body, ok := r.Context().Value(cryptographer.BodyCtxKey).([]byte)
+	if !ok {
+		s.server.OnError(w, r, orbital.ErrBadPayload)
+		return
+	}
+
+	var req CheckReq
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.server.OnError(w, r, fmt.Errorf("%w:[%v]", orbital.ErrUnmarshalPayload, err))
+			return
+		}
+	}
+
+	res, err := s.service.Check(r.Context(), req)
+	if err != nil {
+		s.server.OnError(w, r, err)
+		return
+	}
+
+	s.server.Reply(w, r, cryptographer.Metadata{
+		Domain: Domain,
+		Action: ActionCheck,
+	}, res)
+}
@@ -1,47 +1,76 @@
 package auth
 
 import (
-	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"orbital/orbital"
 	"orbital/pkg/cryptographer"
 )
 
-func MessageDecode() orbital.Middleware {
+func MessageDecode(server orbital.HTTPService) orbital.Middleware {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
+			// The batch envelope was verified, the call body and public key are in the context
+			if orbital.InBatch(r.Context()) {
+				next(w, r)
+				return
+			}
+
+			// Admin socket peers are identified by their credentials and send the bare body
+			if _, local := orbital.LocalAdmin(r.Context()); local {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					server.OnError(w, r, fmt.Errorf("%w:[%v]", orbital.ErrBadPayload, err))
+					return
+				}
+
+				next(w, r.WithContext(orbital.WithCaller(r.Context(), "", body)))
+				return
+			}
+
 			var msg cryptographer.Message
 			if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
-				http.Error(w, "bad JSON envelope", 400)
+				server.OnError(w, r, orbital.NewError(orbital.InvalidRequest, "auth.badEnvelope", "bad JSON envelope").WithCause(err))
 				return
 			}
 			valid, err := msg.Verify()
 			if err != nil {
-				http.Error(w, err.Error(), 400)
+				server.OnError(w, r, orbital.NewError(orbital.InvalidRequest, "auth.badEnvelope", "cannot verify envelope").WithCause(err))
 				return
 			}
 
 			if !valid {
-				http.Error(w, "bad JSON envelope", 400)
+				orbital.RecordSignatureFailure("http")
+				server.OnError(w, r, orbital.NewError(orbital.Unauthenticated, "auth.badSignature", "invalid envelope signature"))
 				return
 			}
 
-			ctx := r.Context()
-			ctx = context.WithValue(ctx, cryptographer.BodyCtxKey, msg.Body)
-			ctx = context.WithValue(ctx, cryptographer.PublicKeyCtxKey, hex.EncodeToString(msg.PublicKey[:]))
+			publicKey := hex.EncodeToString(msg.PublicKey[:])
+			if err = server.AllowKey(publicKey); err != nil {
+				server.OnError(w, r, err)
+				return
+			}
 
-			next(w, r.WithContext(ctx))
+			next(w, r.WithContext(orbital.WithCaller(r.Context(), publicKey, msg.Body)))
 		}
 	}
 }
 
-func ValidateRole() orbital.Middleware {
+// ValidateRole check the envelope signer holds the route permission. Must run after MessageDecode
+func ValidateRole(server orbital.HTTPService) orbital.Middleware {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
-			fmt.Println("ValidateRole unhandled. Coming Soon!")
+			ctx := r.Context()
+
+			publicKey, _ := ctx.Value(cryptographer.PublicKeyCtxKey).(string)
+			if err := server.Authorize(ctx, publicKey, orbital.RoutePermission(ctx)); err != nil {
+				server.OnError(w, r, err)
+				return
+			}
+
 			next(w, r)
 		}
 	}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"orbital/domain"
+	"orbital/orbital"
+	"strings"
+)
+
+// rolePermissions permissions granted to each user access level. "*" grants everything.
+// Unknown access levels get nothing
+var rolePermissions = map[string][]string{
+	// Everything, including the config reload and the audit chain check
+	"root": {"*"},
+	// Operates the node: apps, machine, sessions and the audit log
+	"admin": {"apps:*", "machine:*", "sessions:*", "audit:query", "system:describe", "system:info", "system:rateLimits"},
+	// Read only dashboards
+	"user": {"apps:list", "machine:stats", "machine:containers", "system:describe", "system:info"},
+}
+
+type RBAC struct {
+	userRepo *domain.UserRepository
+}
+
+func NewRBAC(userRepo *domain.UserRepository) *RBAC {
+	return &RBAC{userRepo: userRepo}
+}
+
+// Authorize check that the user owning the public key holds the permission
+func (rbac *RBAC) Authorize(ctx context.Context, publicKey, permission string) error {
+	if permission == "" {
+		return nil
+	}
+
+	user, err := rbac.userRepo.GetByPublicKey(ctx, publicKey)
+	if err != nil {
+		return orbital.NewError(orbital.Unauthenticated, "auth.unknownKey", "unknown public key").WithCause(err)
+	}
+
+	for _, granted := range rolePermissions[user.Access] {
+		if grants(granted, permission) {
+			return nil
+		}
+	}
+
+	return orbital.NewError(orbital.PermissionDenied, "auth.permissionDenied", "permission denied").
+		WithDetails(map[string]any{"permission": permission})
+}
+
+// grants report whether the granted permission covers the requested one: "*", "domain:*" or an exact match
+func grants(granted, permission string) bool {
+	if granted == "*" || granted == permission {
+		return true
+	}
+
+	domainName, found := strings.CutSuffix(granted, ":*")
+	return found && strings.HasPrefix(permission, domainName+":")
+}
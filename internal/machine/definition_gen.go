@@ -0,0 +1,98 @@
+// Code generated by orbital gen. DO NOT EDIT.
+// Source: MachineService schema
+
+package machine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"orbital/internal/auth"
+	"orbital/orbital"
+	"orbital/pkg/cryptographer"
+)
+
+const (
+	Domain           = "machine"
+	ActionContainers = "containers"
+)
+
+// MachineService reports the host and the containers managed by the node.
+type MachineService interface {
+	// Containers List the containers managed by the node.
+	Containers(ctx context.Context, req ContainersReq) (*ContainersResp, error)
+}
+
+// Container managed container as listed by the docker daemon
+type Container struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Image   string `json:"image"`
+	State   string `json:"state"`  // created, running, exited...
+	Status  string `json:"status"` // Human readable, e.g. "Up 2 hours"
+	Created int64  `json:"created"`
+}
+
+type ContainersReq struct {
+}
+
+type ContainersResp struct {
+	Containers []Container            `json:"containers"`
+	Code       orbital.Code           `json:"code"`
+	Error      *orbital.ErrorResponse `json:"error,omitempty"`
+}
+
+type machineServiceServer struct {
+	server  orbital.HTTPService
+	service MachineService
+}
+
+func RegisterMachineServiceServer(server orbital.HTTPService, _ orbital.WsService, service MachineService) {
+	handler := &machineServiceServer{
+		server:  server,
+		service: service,
+	}
+
+	group := server.Group("MachineService",
+		auth.MessageDecode(server),
+		auth.ValidateRole(server),
+	)
+
+	group.Register(orbital.Route{
+		ActionName:  "Containers",
+		Handler:     handler.handleContainers,
+		Method:      http.MethodPost,
+		Permission:  "machine:containers",
+		Description: "List the containers managed by the node.",
+		Request:     ContainersReq{},
+		Response:    ContainersResp{},
+	})
+}
+
+func (s *machineServiceServer) handleContainers(w http.ResponseWriter, r *http.Request) {
+	body, ok := r.Context().Value(cryptographer.BodyCtxKey).([]byte)
+	if !ok {
+		s.server.OnError(w, r, orbital.ErrBadPayload)
+		return
+	}
+
+	var req ContainersReq
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.server.OnError(w, r, fmt.Errorf("%w:[%v]", orbital.ErrUnmarshalPayload, err))
+			return
+		}
+	}
+
+	res, err := s.service.Containers(r.Context(), req)
+	if err != nil {
+		s.server.OnError(w, r, err)
+		return
+	}
+
+	s.server.Reply(w, r, cryptographer.Metadata{
+		Domain: Domain,
+		Action: ActionContainers,
+	}, res)
+}
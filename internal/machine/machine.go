@@ -2,53 +2,137 @@ package machine
 
 import (
 	"context"
-	"orbital/config"
 	"orbital/orbital"
+	"orbital/pkg/agent"
 	"orbital/pkg/cryptographer"
 	"orbital/pkg/jobber"
 	"orbital/pkg/logger"
+	"time"
+)
+
+//go:generate go run orbital/tools/rpcgen machine.rpc.yaml
+
+const (
+	statsInterval = 5 * time.Second
+	watchRetry    = 10 * time.Second // Wait before watching the docker events again
 )
 
 type Dependencies struct {
-	Log *logger.Logger
-	Ws  *orbital.WsConn
+	Log    *logger.Logger
+	Ws     *orbital.WsConn
+	Signer cryptographer.Signer
+	Docker *agent.Docker // Nil when the daemon is not reachable
 }
 
 type Machine struct {
-	jr  *jobber.Runner
-	log *logger.Logger
-	ws  *orbital.WsConn
+	jr     *jobber.Runner
+	log    *logger.Logger
+	ws     *orbital.WsConn
+	signer cryptographer.Signer
+	docker *agent.Docker
+	cancel context.CancelFunc
 }
 
 func NewService(deps Dependencies) *Machine {
+	ctx, cancel := context.WithCancel(context.Background())
+
 	m := &Machine{
-		jr:  jobber.New(5),
-		log: deps.Log,
-		ws:  deps.Ws,
+		jr:     jobber.New(5),
+		log:    deps.Log,
+		ws:     deps.Ws,
+		signer: deps.Signer,
+		docker: deps.Docker,
+		cancel: cancel,
 	}
 
-	//m.jr.AddJob(10*time.Second, jobber.MaxRunInfinite, func() {
-	//	_ = m.JobAllData(context.Background(), AllDataReq{})
-	//})
+	// Stats are only collected while someone watches them
+	m.jr.AddJob(statsInterval, jobber.MaxRunInfinite, func() {
+		if m.ws.HasSubscribers(Domain + "/" + ActionJobAllData) {
+			_ = m.JobAllData(context.Background(), AllDataReq{})
+		}
+	})
+
+	if m.docker != nil {
+		go m.watchContainers(ctx)
+	}
 
 	return m
 }
 
-func (service *Machine) JobAllData(ctx context.Context, req AllDataReq) error {
+// Close stop the stats job and the container events watcher
+func (service *Machine) Close() {
+	service.cancel()
+	service.jr.Shutdown()
+}
 
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		return err
+func (service *Machine) Containers(ctx context.Context, req ContainersReq) (*ContainersResp, error) {
+	if service.docker == nil {
+		return nil, orbital.NewError(orbital.Unavailable, "machine.dockerUnavailable", "docker daemon not reachable")
 	}
 
-	sk, err := cryptographer.NewPrivateKeyFromHex(cfg.SecretKey)
+	containers, err := service.docker.ListContainers()
 	if err != nil {
-		return err
+		return nil, orbital.NewError(orbital.Unavailable, "machine.dockerUnavailable", "cannot list containers").WithCause(err)
+	}
+
+	res := &ContainersResp{Containers: []Container{}, Code: orbital.OK}
+	for _, c := range containers {
+		res.Containers = append(res.Containers, Container{
+			ID:      c.ID,
+			Name:    c.Name,
+			Image:   c.Image,
+			State:   c.State,
+			Status:  c.Status,
+			Created: c.Created,
+		})
+	}
+
+	return res, nil
+}
+
+// watchContainers publish the lifecycle events of the managed containers, watching again when the stream fails
+func (service *Machine) watchContainers(ctx context.Context) {
+	meta := cryptographer.Metadata{
+		Domain: Domain,
+		Action: ActionContainerEvent,
+	}
+
+	failing := false
+	for {
+		err := service.docker.WatchContainers(ctx, func(e agent.ContainerEvent) {
+			failing = false
+			service.publish(ctx, meta, ContainerEvent{
+				ID:     e.ID,
+				Name:   e.Name,
+				Image:  e.Image,
+				Action: e.Action,
+				Time:   e.Time.UnixNano(),
+				Code:   orbital.OK,
+			})
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Logged once until the stream works again, the daemon may be down for long
+		if !failing {
+			service.log.Error("container events stream failed", "err", err)
+		}
+		failing = true
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchRetry):
+		}
 	}
+}
+
+func (service *Machine) JobAllData(ctx context.Context, req AllDataReq) error {
 
 	meta := cryptographer.Metadata{
-		Domain: "machine",
-		Action: "jobAllData",
+		Domain: Domain,
+		Action: ActionJobAllData,
 	}
 
 	body := &AllDataResp{}
@@ -61,8 +145,7 @@ func (service *Machine) JobAllData(ctx context.Context, req AllDataReq) error {
 			Msg:  err.Error(),
 		}
 
-		msg, _ := cryptographer.Encode(sk, meta, body)
-		service.ws.Broadcast(ctx, *msg)
+		service.publish(ctx, meta, body)
 	}
 
 	cpu, err := getCPUInfo()
@@ -73,8 +156,7 @@ func (service *Machine) JobAllData(ctx context.Context, req AllDataReq) error {
 			Msg:  err.Error(),
 		}
 
-		msg, _ := cryptographer.Encode(sk, meta, body)
-		service.ws.Broadcast(ctx, *msg)
+		service.publish(ctx, meta, body)
 	}
 
 	mem, err := getMemInfo()
@@ -84,8 +166,7 @@ func (service *Machine) JobAllData(ctx context.Context, req AllDataReq) error {
 			Type: "machine.stats.err",
 			Msg:  err.Error(),
 		}
-		msg, _ := cryptographer.Encode(sk, meta, body)
-		service.ws.Broadcast(ctx, *msg)
+		service.publish(ctx, meta, body)
 	}
 
 	netwk, err := getNetworkInfo()
@@ -96,8 +177,7 @@ func (service *Machine) JobAllData(ctx context.Context, req AllDataReq) error {
 			Msg:  err.Error(),
 		}
 
-		msg, _ := cryptographer.Encode(sk, meta, body)
-		service.ws.Broadcast(ctx, *msg)
+		service.publish(ctx, meta, body)
 	}
 
 	disk, err := getDiskInfo()
@@ -107,8 +187,7 @@ func (service *Machine) JobAllData(ctx context.Context, req AllDataReq) error {
 			Type: "machine.stats.err",
 			Msg:  err.Error(),
 		}
-		msg, _ := cryptographer.Encode(sk, meta, body)
-		service.ws.Broadcast(ctx, *msg)
+		service.publish(ctx, meta, body)
 	}
 
 	stats := &SystemInfo{
@@ -122,8 +201,18 @@ func (service *Machine) JobAllData(ctx context.Context, req AllDataReq) error {
 	body.Code = orbital.OK
 	body.SystemInfo = stats
 
-	msg, _ := cryptographer.Encode(sk, meta, body)
-	service.ws.Broadcast(ctx, *msg)
+	service.publish(ctx, meta, body)
 
 	return nil
 }
+
+// publish sign the body and send it to the connections subscribed to the topic
+func (service *Machine) publish(ctx context.Context, meta cryptographer.Metadata, body any) {
+	msg, err := cryptographer.Encode(service.signer, meta, body)
+	if err != nil {
+		service.log.ErrorContext(ctx, "cannot encode message", "domain", meta.Domain, "action", meta.Action, "err", err)
+		return
+	}
+
+	service.ws.Publish(ctx, meta.Domain+"/"+meta.Action, *msg)
+}
@@ -0,0 +1,47 @@
+package machine
+
+import (
+	"strconv"
+
+	"orbital/pkg/metrics"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/host"
+)
+
+// Host stats read on every scrape. CPU load is measured since the previous scrape
+var (
+	_ = metrics.NewGaugeFunc("orbital_host_cpu_load_percent", "CPU load per core since the previous scrape",
+		func(emit metrics.EmitFunc) {
+			loads, err := cpu.Percent(0, true)
+			if err != nil {
+				return
+			}
+			for i, l := range loads {
+				emit(l, strconv.Itoa(i))
+			}
+		}, "core")
+
+	_ = metrics.NewGaugeFunc("orbital_host_memory_bytes", "Memory by state: total or available",
+		func(emit metrics.EmitFunc) {
+			m, _ := getMemInfo()
+			emit(float64(m.Total), "total")
+			emit(float64(m.Free), "available")
+		}, "state")
+
+	_ = metrics.NewGaugeFunc("orbital_host_disk_bytes", "Disk space by device and state: total or free",
+		func(emit metrics.EmitFunc) {
+			d, _ := getDiskInfo()
+			for _, disk := range d.Disks {
+				emit(float64(disk.Total), disk.Name, "total")
+				emit(float64(disk.Free), disk.Name, "free")
+			}
+		}, "device", "state")
+
+	_ = metrics.NewGaugeFunc("orbital_host_uptime_seconds", "Host uptime",
+		func(emit metrics.EmitFunc) {
+			if uptime, err := host.Uptime(); err == nil {
+				emit(float64(uptime))
+			}
+		})
+)
@@ -0,0 +1,49 @@
+package machine
+
+import "orbital/orbital"
+
+const (
+	ActionJobAllData     = "jobAllData"
+	ActionContainerEvent = "containerEvent"
+)
+
+type AllDataReq struct {
+}
+
+// SystemInfo basic aggregation struct
+// TODO: Detail this with properties for better experience latter on
+type SystemInfo map[string]any
+
+type AllDataResp struct {
+	SystemInfo *SystemInfo            `json:"systemInfo,omitempty"`
+	Code       orbital.Code           `json:"code"`
+	Error      *orbital.ErrorResponse `json:"error,omitempty"`
+}
+
+// ContainerEvent published on machine/containerEvent when a managed container changes state
+type ContainerEvent struct {
+	ID     string                 `json:"id"`
+	Name   string                 `json:"name"`
+	Image  string                 `json:"image"`
+	Action string                 `json:"action"` // create, start, die, stop, destroy, health_status...
+	Time   int64                  `json:"time"`   // Unix nanoseconds
+	Code   orbital.Code           `json:"code"`
+	Error  *orbital.ErrorResponse `json:"error,omitempty"`
+}
+
+// RegisterMachineTopics register the topics published by the service
+func RegisterMachineTopics(wsServer orbital.WsService) {
+	wsServer.Register(orbital.Topic{
+		Name:        Domain + "/" + ActionJobAllData,
+		Permission:  "machine:stats",
+		Description: "Host stats published periodically to subscribers",
+		Request:     AllDataResp{},
+	})
+
+	wsServer.Register(orbital.Topic{
+		Name:        Domain + "/" + ActionContainerEvent,
+		Permission:  "machine:containers",
+		Description: "Lifecycle events of the managed containers. Published to subscribers",
+		Request:     ContainerEvent{},
+	})
+}
@@ -0,0 +1,219 @@
+// Code generated by orbital gen. DO NOT EDIT.
+// Source: SessionsService schema
+
+package sessions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"orbital/internal/auth"
+	"orbital/orbital"
+	"orbital/pkg/cryptographer"
+)
+
+const (
+	Domain       = "sessions"
+	ActionList   = "list"
+	ActionKick   = "kick"
+	ActionNotice = "notice"
+)
+
+// SessionsService lets operators inspect and manage the live websocket sessions.
+type SessionsService interface {
+	// List the open websocket connections.
+	List(ctx context.Context, req ListReq) (*ListResp, error)
+	// Kick Close a websocket connection.
+	Kick(ctx context.Context, req KickReq) (*KickResp, error)
+	// Notice Broadcast an admin notice to every connection.
+	Notice(ctx context.Context, req NoticeReq) (*NoticeResp, error)
+}
+
+// Session an open websocket connection
+type Session struct {
+	ConnID      string `json:"connId"`
+	UserID      string `json:"userId"` // Empty until the connection is authenticated
+	Role        string `json:"role"`
+	RemoteAddr  string `json:"remoteAddr"`
+	ConnectedAt int64  `json:"connectedAt"` // Unix seconds
+	LastPong    int64  `json:"lastPong"`    // Unix seconds of the last answered ping
+	BytesIn     uint64 `json:"bytesIn"`
+	BytesOut    uint64 `json:"bytesOut"`
+	MessagesIn  uint64 `json:"messagesIn"`
+	MessagesOut uint64 `json:"messagesOut"`
+	Dropped     uint64 `json:"dropped"` // Messages dropped because the send queue was full
+	Queued      int    `json:"queued"`
+}
+
+// Notice admin message broadcast on sessions/notice
+type Notice struct {
+	Message string `json:"message"`
+	Level   string `json:"level"` // info, warning or error
+	Time    int64  `json:"time"`  // Unix seconds
+}
+
+// Presence published on sessions/presence when an authenticated connection joins or leaves
+type Presence struct {
+	ConnID string   `json:"connId"`
+	UserID string   `json:"userId"`
+	Online bool     `json:"online"`
+	Users  []string `json:"users"` // Users online after the change
+}
+
+type ListReq struct {
+}
+
+type ListResp struct {
+	Sessions []Session              `json:"sessions"`
+	Code     orbital.Code           `json:"code"`
+	Error    *orbital.ErrorResponse `json:"error,omitempty"`
+}
+
+type KickReq struct {
+	ConnID string `json:"connId"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type KickResp struct {
+	Code  orbital.Code           `json:"code"`
+	Error *orbital.ErrorResponse `json:"error,omitempty"`
+}
+
+type NoticeReq struct {
+	Message string `json:"message"`
+	Level   string `json:"level,omitempty"` // Defaults to info
+}
+
+type NoticeResp struct {
+	Code  orbital.Code           `json:"code"`
+	Error *orbital.ErrorResponse `json:"error,omitempty"`
+}
+
+type sessionsServiceServer struct {
+	server  orbital.HTTPService
+	service SessionsService
+}
+
+func RegisterSessionsServiceServer(server orbital.HTTPService, _ orbital.WsService, service SessionsService) {
+	handler := &sessionsServiceServer{
+		server:  server,
+		service: service,
+	}
+
+	group := server.Group("SessionsService",
+		auth.MessageDecode(server),
+		auth.ValidateRole(server),
+	)
+
+	group.Register(orbital.Route{
+		ActionName:  "List",
+		Handler:     handler.handleList,
+		Method:      http.MethodPost,
+		Permission:  "sessions:list",
+		Description: "List the open websocket connections.",
+		Request:     ListReq{},
+		Response:    ListResp{},
+	})
+
+	group.Register(orbital.Route{
+		ActionName:  "Kick",
+		Handler:     handler.handleKick,
+		Method:      http.MethodPost,
+		Permission:  "sessions:kick",
+		Description: "Close a websocket connection.",
+		Request:     KickReq{},
+		Response:    KickResp{},
+	})
+
+	group.Register(orbital.Route{
+		ActionName:  "Notice",
+		Handler:     handler.handleNotice,
+		Method:      http.MethodPost,
+		Permission:  "sessions:notice",
+		Description: "Broadcast an admin notice to every connection.",
+		Request:     NoticeReq{},
+		Response:    NoticeResp{},
+	})
+}
+
+func (s *sessionsServiceServer) handleList(w http.ResponseWriter, r *http.Request) {
+	body, ok := r.Context().Value(cryptographer.BodyCtxKey).([]byte)
+	if !ok {
+		s.server.OnError(w, r, orbital.ErrBadPayload)
+		return
+	}
+
+	var req ListReq
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.server.OnError(w, r, fmt.Errorf("%w:[%v]", orbital.ErrUnmarshalPayload, err))
+			return
+		}
+	}
+
+	res, err := s.service.List(r.Context(), req)
+	if err != nil {
+		s.server.OnError(w, r, err)
+		return
+	}
+
+	s.server.Reply(w, r, cryptographer.Metadata{
+		Domain: Domain,
+		Action: ActionList,
+	}, res)
+}
+
+func (s *sessionsServiceServer) handleKick(w http.ResponseWriter, r *http.Request) {
+	body, ok := r.Context().Value(cryptographer.BodyCtxKey).([]byte)
+	if !ok {
+		s.server.OnError(w, r, orbital.ErrBadPayload)
+		return
+	}
+
+	var req KickReq
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.server.OnError(w, r, fmt.Errorf("%w:[%v]", orbital.ErrUnmarshalPayload, err))
+			return
+		}
+	}
+
+	res, err := s.service.Kick(r.Context(), req)
+	if err != nil {
+		s.server.OnError(w, r, err)
+		return
+	}
+
+	s.server.Reply(w, r, cryptographer.Metadata{
+		Domain: Domain,
+		Action: ActionKick,
+	}, res)
+}
+
+func (s *sessionsServiceServer) handleNotice(w http.ResponseWriter, r *http.Request) {
+	body, ok := r.Context().Value(cryptographer.BodyCtxKey).([]byte)
+	if !ok {
+		s.server.OnError(w, r, orbital.ErrBadPayload)
+		return
+	}
+
+	var req NoticeReq
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.server.OnError(w, r, fmt.Errorf("%w:[%v]", orbital.ErrUnmarshalPayload, err))
+			return
+		}
+	}
+
+	res, err := s.service.Notice(r.Context(), req)
+	if err != nil {
+		s.server.OnError(w, r, err)
+		return
+	}
+
+	s.server.Reply(w, r, cryptographer.Metadata{
+		Domain: Domain,
+		Action: ActionNotice,
+	}, res)
+}
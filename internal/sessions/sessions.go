@@ -0,0 +1,128 @@
+package sessions
+
+import (
+	"context"
+	"orbital/orbital"
+	"orbital/pkg/cryptographer"
+	"orbital/pkg/logger"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:generate go run orbital/tools/rpcgen sessions.rpc.yaml
+
+const (
+	TopicPresence = "sessions/presence"
+	TopicNotice   = "sessions/notice"
+)
+
+type Dependencies struct {
+	Log    *logger.Logger
+	Ws     orbital.WsService
+	Signer cryptographer.Signer
+}
+
+type Sessions struct {
+	log    *logger.Logger
+	ws     orbital.WsService
+	signer cryptographer.Signer
+}
+
+func NewService(deps Dependencies) *Sessions {
+	s := &Sessions{
+		log:    deps.Log,
+		ws:     deps.Ws,
+		signer: deps.Signer,
+	}
+
+	s.ws.OnPresence(s.publishPresence)
+
+	return s
+}
+
+func (service *Sessions) List(_ context.Context, _ ListReq) (*ListResp, error) {
+	conns := service.ws.Connections()
+	sort.Slice(conns, func(i, j int) bool {
+		return conns[i].ConnectedAt.Before(conns[j].ConnectedAt)
+	})
+
+	sessions := make([]Session, 0, len(conns))
+	for _, c := range conns {
+		sessions = append(sessions, Session{
+			ConnID:      c.ID,
+			UserID:      c.UserID,
+			Role:        c.Role,
+			RemoteAddr:  c.RemoteAddr,
+			ConnectedAt: c.ConnectedAt.Unix(),
+			LastPong:    c.LastPong.Unix(),
+			BytesIn:     c.BytesIn,
+			BytesOut:    c.BytesOut,
+			MessagesIn:  c.MessagesIn,
+			MessagesOut: c.MessagesOut,
+			Dropped:     c.Dropped,
+			Queued:      c.Queued,
+		})
+	}
+
+	return &ListResp{
+		Code:     orbital.OK,
+		Sessions: sessions,
+	}, nil
+}
+
+func (service *Sessions) Kick(ctx context.Context, req KickReq) (*KickResp, error) {
+	if err := service.ws.Kick(req.ConnID, req.Reason); err != nil {
+		return nil, err
+	}
+
+	service.log.InfoContext(ctx, "connection kicked", "kickedConnId", req.ConnID, "reason", req.Reason)
+
+	return &KickResp{Code: orbital.OK}, nil
+}
+
+func (service *Sessions) Notice(ctx context.Context, req NoticeReq) (*NoticeResp, error) {
+	if strings.TrimSpace(req.Message) == "" {
+		return nil, orbital.NewError(orbital.InvalidRequest, "sessions.emptyNotice", "notice message is required")
+	}
+
+	level := req.Level
+	if level == "" {
+		level = "info"
+	}
+
+	msg, err := cryptographer.Encode(service.signer, cryptographer.Metadata{
+		Domain: Domain,
+		Action: "notice",
+	}, Notice{
+		Message: req.Message,
+		Level:   level,
+		Time:    time.Now().Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	service.ws.Broadcast(ctx, *msg)
+
+	return &NoticeResp{Code: orbital.OK}, nil
+}
+
+// publishPresence tell the sessions/presence subscribers who is online
+func (service *Sessions) publishPresence(ctx context.Context, event orbital.PresenceEvent) {
+	msg, err := cryptographer.Encode(service.signer, cryptographer.Metadata{
+		Domain: Domain,
+		Action: "presence",
+	}, Presence{
+		ConnID: event.ConnID,
+		UserID: event.UserID,
+		Online: event.Online,
+		Users:  service.ws.OnlineUsers(),
+	})
+	if err != nil {
+		service.log.ErrorContext(ctx, "cannot encode presence", "err", err)
+		return
+	}
+
+	service.ws.Publish(ctx, TopicPresence, *msg)
+}
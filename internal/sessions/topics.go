@@ -0,0 +1,19 @@
+package sessions
+
+import "orbital/orbital"
+
+// RegisterSessionsTopics register the topics published by the service
+func RegisterSessionsTopics(wsServer orbital.WsService) {
+	wsServer.Register(orbital.Topic{
+		Name:        TopicPresence,
+		Permission:  "sessions:presence",
+		Description: "Users joining or leaving. Published to subscribers",
+		Request:     Presence{},
+	})
+
+	wsServer.Register(orbital.Topic{
+		Name:        TopicNotice,
+		Description: "Admin notices. Sent to every connection",
+		Request:     Notice{},
+	})
+}
@@ -0,0 +1,243 @@
+// Code generated by orbital gen. DO NOT EDIT.
+// Source: SystemService schema
+
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"orbital/config"
+	"orbital/internal/auth"
+	"orbital/orbital"
+	"orbital/pkg/buildinfo"
+	"orbital/pkg/cryptographer"
+)
+
+const (
+	Domain           = "system"
+	ActionDescribe   = "describe"
+	ActionRateLimits = "rateLimits"
+	ActionInfo       = "info"
+	ActionReload     = "reload"
+)
+
+// SystemService describes and operates the node itself.
+type SystemService interface {
+	// Describe the routes, topics and schemas exposed by the node.
+	Describe(ctx context.Context, req DescribeReq) (*DescribeResp, error)
+	// RateLimits report the rate limiters settings and current buckets.
+	RateLimits(ctx context.Context, req RateLimitsReq) (*RateLimitsResp, error)
+	// Info report the build metadata and uptime of the node.
+	Info(ctx context.Context, req InfoReq) (*InfoResp, error)
+	// Reload the config file, apply the reloadable sections and return the changes.
+	Reload(ctx context.Context, req ReloadReq) (*ReloadResp, error)
+}
+
+type DescribeReq struct {
+	OpenAPI bool `json:"openapi"` // Include the OpenAPI document
+}
+
+type DescribeResp struct {
+	Routes  []orbital.RouteInfo    `json:"routes"`
+	Topics  []orbital.TopicInfo    `json:"topics"`
+	OpenAPI map[string]any         `json:"openapi,omitempty"`
+	Code    orbital.Code           `json:"code"`
+	Error   *orbital.ErrorResponse `json:"error,omitempty"`
+}
+
+type RateLimitsReq struct {
+}
+
+type RateLimitsResp struct {
+	State orbital.RateLimiterState `json:"state"`
+	Code  orbital.Code             `json:"code"`
+	Error *orbital.ErrorResponse   `json:"error,omitempty"`
+}
+
+type InfoReq struct {
+}
+
+type InfoResp struct {
+	Build         buildinfo.Info         `json:"build"`
+	UptimeSeconds int64                  `json:"uptimeSeconds"`
+	PublicKey     string                 `json:"publicKey"` // Node key signing the replies
+	Code          orbital.Code           `json:"code"`
+	Error         *orbital.ErrorResponse `json:"error,omitempty"`
+}
+
+type ReloadReq struct {
+}
+
+type ReloadResp struct {
+	Changes []config.Change        `json:"changes"` // Values that differ from the running config
+	Restart bool                   `json:"restart"` // Some changes are not applied until the node restarts
+	Code    orbital.Code           `json:"code"`
+	Error   *orbital.ErrorResponse `json:"error,omitempty"`
+}
+
+type systemServiceServer struct {
+	server  orbital.HTTPService
+	service SystemService
+}
+
+func RegisterSystemServiceServer(server orbital.HTTPService, _ orbital.WsService, service SystemService) {
+	handler := &systemServiceServer{
+		server:  server,
+		service: service,
+	}
+
+	group := server.Group("SystemService",
+		auth.MessageDecode(server),
+		auth.ValidateRole(server),
+	)
+
+	group.Register(orbital.Route{
+		ActionName:  "Describe",
+		Handler:     handler.handleDescribe,
+		Method:      http.MethodPost,
+		Permission:  "system:describe",
+		Description: "Describe the routes, topics and schemas exposed by the node.",
+		Request:     DescribeReq{},
+		Response:    DescribeResp{},
+	})
+
+	group.Register(orbital.Route{
+		ActionName:  "RateLimits",
+		Handler:     handler.handleRateLimits,
+		Method:      http.MethodPost,
+		Permission:  "system:rateLimits",
+		Description: "RateLimits report the rate limiters settings and current buckets.",
+		Request:     RateLimitsReq{},
+		Response:    RateLimitsResp{},
+	})
+
+	group.Register(orbital.Route{
+		ActionName:  "Info",
+		Handler:     handler.handleInfo,
+		Method:      http.MethodPost,
+		Permission:  "system:info",
+		Description: "Info report the build metadata and uptime of the node.",
+		Request:     InfoReq{},
+		Response:    InfoResp{},
+	})
+
+	group.Register(orbital.Route{
+		ActionName:  "Reload",
+		Handler:     handler.handleReload,
+		Method:      http.MethodPost,
+		Permission:  "system:reload",
+		Description: "Reload the config file, apply the reloadable sections and return the changes.",
+		Request:     ReloadReq{},
+		Response:    ReloadResp{},
+	})
+}
+
+func (s *systemServiceServer) handleDescribe(w http.ResponseWriter, r *http.Request) {
+	body, ok := r.Context().Value(cryptographer.BodyCtxKey).([]byte)
+	if !ok {
+		s.server.OnError(w, r, orbital.ErrBadPayload)
+		return
+	}
+
+	var req DescribeReq
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.server.OnError(w, r, fmt.Errorf("%w:[%v]", orbital.ErrUnmarshalPayload, err))
+			return
+		}
+	}
+
+	res, err := s.service.Describe(r.Context(), req)
+	if err != nil {
+		s.server.OnError(w, r, err)
+		return
+	}
+
+	s.server.Reply(w, r, cryptographer.Metadata{
+		Domain: Domain,
+		Action: ActionDescribe,
+	}, res)
+}
+
+func (s *systemServiceServer) handleRateLimits(w http.ResponseWriter, r *http.Request) {
+	body, ok := r.Context().Value(cryptographer.BodyCtxKey).([]byte)
+	if !ok {
+		s.server.OnError(w, r, orbital.ErrBadPayload)
+		return
+	}
+
+	var req RateLimitsReq
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.server.OnError(w, r, fmt.Errorf("%w:[%v]", orbital.ErrUnmarshalPayload, err))
+			return
+		}
+	}
+
+	res, err := s.service.RateLimits(r.Context(), req)
+	if err != nil {
+		s.server.OnError(w, r, err)
+		return
+	}
+
+	s.server.Reply(w, r, cryptographer.Metadata{
+		Domain: Domain,
+		Action: ActionRateLimits,
+	}, res)
+}
+
+func (s *systemServiceServer) handleInfo(w http.ResponseWriter, r *http.Request) {
+	body, ok := r.Context().Value(cryptographer.BodyCtxKey).([]byte)
+	if !ok {
+		s.server.OnError(w, r, orbital.ErrBadPayload)
+		return
+	}
+
+	var req InfoReq
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.server.OnError(w, r, fmt.Errorf("%w:[%v]", orbital.ErrUnmarshalPayload, err))
+			return
+		}
+	}
+
+	res, err := s.service.Info(r.Context(), req)
+	if err != nil {
+		s.server.OnError(w, r, err)
+		return
+	}
+
+	s.server.Reply(w, r, cryptographer.Metadata{
+		Domain: Domain,
+		Action: ActionInfo,
+	}, res)
+}
+
+func (s *systemServiceServer) handleReload(w http.ResponseWriter, r *http.Request) {
+	body, ok := r.Context().Value(cryptographer.BodyCtxKey).([]byte)
+	if !ok {
+		s.server.OnError(w, r, orbital.ErrBadPayload)
+		return
+	}
+
+	var req ReloadReq
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.server.OnError(w, r, fmt.Errorf("%w:[%v]", orbital.ErrUnmarshalPayload, err))
+			return
+		}
+	}
+
+	res, err := s.service.Reload(r.Context(), req)
+	if err != nil {
+		s.server.OnError(w, r, err)
+		return
+	}
+
+	s.server.Reply(w, r, cryptographer.Metadata{
+		Domain: Domain,
+		Action: ActionReload,
+	}, res)
+}
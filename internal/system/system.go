@@ -2,57 +2,63 @@ package system
 
 import (
 	"context"
-	"orbital/config"
 	"orbital/orbital"
+	"orbital/pkg/buildinfo"
 	"orbital/pkg/cryptographer"
 	"orbital/pkg/logger"
 )
 
-const (
-	Domain              = "system"
-	ActionKeepAlivePing = "keepAlivePing"
-	ActionKeepAlivePong = "keepAlivePong"
-	ActionWelcome       = "welcome"
-)
+//go:generate go run orbital/tools/rpcgen system.rpc.yaml
+
+// apiVersion reported in the OpenAPI document
+const apiVersion = "v1"
 
 type Dependencies struct {
-	Log *logger.Logger
-	Ws  *orbital.WsConn
+	Log     *logger.Logger
+	Api     orbital.HTTPService
+	Ws      *orbital.WsConn
+	Signer  cryptographer.Signer
+	Limiter *orbital.RateLimiter
+	Node    *orbital.Orbital
+	Build   buildinfo.Info
 }
 
 type System struct {
-	log *logger.Logger
-	ws  *orbital.WsConn
+	log     *logger.Logger
+	api     orbital.HTTPService
+	ws      *orbital.WsConn
+	signer  cryptographer.Signer
+	limiter *orbital.RateLimiter
+	node    *orbital.Orbital
+	build   buildinfo.Info
 }
 
 func NewService(deps Dependencies) *System {
 	return &System{
-		log: deps.Log,
-		ws:  deps.Ws,
+		log:     deps.Log,
+		api:     deps.Api,
+		ws:      deps.Ws,
+		signer:  deps.Signer,
+		limiter: deps.Limiter,
+		node:    deps.Node,
+		build:   deps.Build,
 	}
 }
 
 func (s *System) ConnectionKeepAlive(ctx context.Context, req ConnectionKeepAliveReq) error {
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		return err
-	}
-
-	sk, err := cryptographer.NewPrivateKeyFromHex(cfg.SecretKey)
-	if err != nil {
-		return err
-	}
-
 	meta := cryptographer.Metadata{
 		Domain: Domain,
 		Action: ActionKeepAlivePong,
 	}
 
-	msg, _ := cryptographer.Encode(sk, meta, ConnectionKeepAliveRes{
+	msg, err := cryptographer.Encode(s.signer, meta, ConnectionKeepAliveRes{
 		Code: orbital.OK,
 	})
+	if err != nil {
+		return err
+	}
 
-	s.log.Debug("keep alive pong", "connId", req.ConnID)
+	s.log.DebugContext(ctx, "keep alive pong")
 
 	if err = s.ws.SendTo(ctx, req.ConnID, *msg); err != nil {
 		return err
@@ -60,3 +66,57 @@ func (s *System) ConnectionKeepAlive(ctx context.Context, req ConnectionKeepAliv
 
 	return nil
 }
+
+// Describe list the routes and topics exposed by the node
+func (s *System) Describe(_ context.Context, req DescribeReq) (*DescribeResp, error) {
+	routes := s.api.Routes()
+	topics := s.ws.Topics()
+
+	res := &DescribeResp{
+		Code:   orbital.OK,
+		Routes: routes,
+		Topics: topics,
+	}
+
+	if req.OpenAPI {
+		res.OpenAPI = orbital.OpenAPI("Orbital node", apiVersion, routes, topics)
+	}
+
+	return res, nil
+}
+
+// RateLimits report the rate limiters settings and current buckets
+func (s *System) RateLimits(_ context.Context, _ RateLimitsReq) (*RateLimitsResp, error) {
+	return &RateLimitsResp{
+		Code:  orbital.OK,
+		State: s.limiter.State(),
+	}, nil
+}
+
+// Info report the build metadata and uptime of the node
+func (s *System) Info(_ context.Context, _ InfoReq) (*InfoResp, error) {
+	return &InfoResp{
+		Code:          orbital.OK,
+		Build:         s.build,
+		UptimeSeconds: int64(buildinfo.Uptime().Seconds()),
+		PublicKey:     s.signer.PublicKey().ToHex(),
+	}, nil
+}
+
+// Reload apply the config file again. Changes needing a restart are reported, not applied
+func (s *System) Reload(_ context.Context, _ ReloadReq) (*ReloadResp, error) {
+	changes, err := s.node.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ReloadResp{
+		Code:    orbital.OK,
+		Changes: changes,
+	}
+	for _, change := range changes {
+		res.Restart = res.Restart || !change.Reloadable
+	}
+
+	return res, nil
+}
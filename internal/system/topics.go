@@ -0,0 +1,42 @@
+package system
+
+import (
+	"context"
+	"orbital/orbital"
+)
+
+const (
+	ActionKeepAlivePing = "keepAlivePing"
+	ActionKeepAlivePong = "keepAlivePong"
+	ActionWelcome       = "welcome"
+)
+
+type ConnectionKeepAliveReq struct {
+	ConnID string `json:"connId"`
+}
+
+type ConnectionKeepAliveRes struct {
+	Code  orbital.Code           `json:"code"`
+	Error *orbital.ErrorResponse `json:"error,omitempty"`
+}
+
+// RegisterSystemTopics register the application level keep alive, answered by the service
+func RegisterSystemTopics(wsServer orbital.WsService, service *System) {
+	wsServer.Register(orbital.Topic{
+		Name:        Domain + "/" + ActionKeepAlivePing,
+		Description: "Application level ping. Answered with system/keepAlivePong",
+		Handler: func(ctx context.Context, connID string, _ []byte) {
+			if err := service.ConnectionKeepAlive(ctx, ConnectionKeepAliveReq{ConnID: connID}); err != nil {
+				service.log.ErrorContext(ctx, "cannot answer keep alive", "err", err)
+			}
+		},
+	})
+
+	wsServer.Register(orbital.Topic{
+		Name:        Domain + "/" + ActionKeepAlivePong,
+		Description: "Application level pong sent by the client",
+		Handler: func(ctx context.Context, connID string, _ []byte) {
+			service.log.DebugContext(ctx, "keep alive pong", "connId", connID)
+		},
+	})
+}
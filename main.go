@@ -4,6 +4,7 @@ import (
 	"embed"
 	"fmt"
 	"orbital/cmd"
+	"orbital/pkg/buildinfo"
 	"orbital/pkg/cryptographer"
 	"os"
 )
@@ -11,6 +12,13 @@ import (
 //go:embed resources/*
 var resourcesDir embed.FS
 
+// Build metadata. Set by the Taskfile ldflags
+var (
+	Version string
+	Compile string
+	Branch  string
+)
+
 func main() {
 	if err := run(); err != nil {
 		fmt.Fprintf(os.Stderr, "%s\n", err)
@@ -32,11 +40,12 @@ func run() error {
 		Body:      body,
 	}
 
-	if err = msg.Sign(sk.Seed()); err != nil {
+	if err = msg.SignWith(sk); err != nil {
 		return fmt.Errorf("cannot sign message: %w", err)
 	}
 
 	return cmd.Execute(cmd.Dependencies{
-		FS: resourcesDir,
+		FS:    resourcesDir,
+		Build: buildinfo.New(Version, Compile, Branch),
 	})
 }
@@ -0,0 +1,159 @@
+package orbital
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"orbital/config"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// AdminPeer credentials of a process connected to the admin socket
+type AdminPeer struct {
+	UID    uint32
+	GID    uint32
+	PID    int32
+	Groups []uint32 // Supplementary groups
+}
+
+// LocalAdmin return the admin socket peer of the request. Such requests carry no signed envelope
+// and hold every permission
+func LocalAdmin(ctx context.Context) (AdminPeer, bool) {
+	peer, ok := ctx.Value(adminPeerCtxKey).(AdminPeer)
+	return peer, ok
+}
+
+// adminServer serve the RPC services and the health probes on a Unix socket.
+// Peers are admins when root, the node user, a listed uid or a member of the socket group, primary or supplementary
+type adminServer struct {
+	srv       *http.Server
+	path      string
+	allowUIDs []uint32
+	gid       int
+}
+
+func (n *Orbital) listenAdmin(cfg config.Admin) (*adminServer, net.Listener, error) {
+	a := &adminServer{
+		path:      cfg.Socket,
+		allowUIDs: append([]uint32{0, uint32(os.Geteuid())}, cfg.AllowUIDs...),
+		gid:       -1,
+	}
+
+	if cfg.Group != "" {
+		group, err := user.LookupGroup(cfg.Group)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w:[%v]", ErrAdminListen, err)
+		}
+		if a.gid, err = strconv.Atoi(group.Gid); err != nil {
+			return nil, nil, fmt.Errorf("%w:[%v]", ErrAdminListen, err)
+		}
+	}
+
+	if err := a.makeDir(); err != nil {
+		return nil, nil, fmt.Errorf("%w:[%v]", ErrAdminListen, err)
+	}
+
+	// A socket left by a crashed node is removed, one still answering belongs to a running node
+	if _, err := os.Stat(a.path); err == nil {
+		if c, err := net.DialTimeout("unix", a.path, time.Second); err == nil {
+			_ = c.Close()
+			return nil, nil, fmt.Errorf("%w:[%s]", ErrAdminSocketInUse, a.path)
+		}
+		_ = os.Remove(a.path)
+	}
+
+	ln, err := net.Listen("unix", a.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w:[%v]", ErrAdminListen, err)
+	}
+
+	if err = os.Chmod(a.path, 0660); err == nil && a.gid >= 0 {
+		err = os.Chown(a.path, -1, a.gid)
+	}
+	if err != nil {
+		_ = ln.Close()
+		return nil, nil, fmt.Errorf("%w:[%v]", ErrAdminListen, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/rpc/", n.apiServer)
+	mux.HandleFunc("/healthz", n.healthHandler(true))
+	mux.HandleFunc("/readyz", n.healthHandler(false))
+
+	a.srv = &http.Server{
+		Handler: a.guard(mux),
+		ConnContext: func(ctx context.Context, c net.Conn) context.Context {
+			peer, err := peerCredentials(c)
+			if err != nil {
+				n.log.Warn("admin socket peer refused", "err", err)
+				return ctx
+			}
+			return context.WithValue(ctx, adminPeerCtxKey, peer)
+		},
+	}
+
+	return a, ln, nil
+}
+
+// makeDir create the socket directory. One created by the node belongs to the socket group so its members
+// can reach the socket, an existing one is left as is
+func (a *adminServer) makeDir() error {
+	dir := filepath.Dir(a.path)
+	if _, err := os.Stat(dir); !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+	if a.gid < 0 {
+		return nil
+	}
+
+	if err := os.Chown(dir, -1, a.gid); err != nil {
+		return err
+	}
+	return os.Chmod(dir, 0750)
+}
+
+// guard refuse the peers that are not admins. The remote address names the peer in logs and audit
+func (a *adminServer) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peer, ok := LocalAdmin(r.Context())
+		if !ok || !a.allowed(peer) {
+			http.Error(w, "not an admin", http.StatusForbidden)
+			return
+		}
+
+		r.RemoteAddr = fmt.Sprintf("unix:uid=%d,pid=%d", peer.UID, peer.PID)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *adminServer) allowed(peer AdminPeer) bool {
+	if slices.Contains(a.allowUIDs, peer.UID) {
+		return true
+	}
+
+	return a.gid >= 0 && (peer.GID == uint32(a.gid) || slices.Contains(peer.Groups, uint32(a.gid)))
+}
+
+func (a *adminServer) serve(ln net.Listener) error {
+	if err := a.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("%w:[%v]", ErrAdminListen, err)
+	}
+	return nil
+}
+
+func (a *adminServer) close() {
+	_ = a.srv.Close()
+	_ = os.Remove(a.path)
+}
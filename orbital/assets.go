@@ -0,0 +1,245 @@
+package orbital
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"orbital/pkg/assets"
+	"orbital/pkg/logger"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	indexFile       = "index.html"
+	cacheImmutable  = "public, max-age=31536000, immutable"
+	cacheRevalidate = "no-cache"
+)
+
+// encodings precompressed variants, by preference
+var encodings = []struct {
+	name string
+	ext  string
+}{
+	{"br", assets.BrotliExt},
+	{"gzip", assets.GzipExt},
+}
+
+// assetServer serve the embedded UI. Content hashed assets are cached for good, the others revalidate with their ETag.
+// Precompressed variants are picked from Accept-Encoding
+type assetServer struct {
+	files    fs.FS
+	manifest assets.Manifest
+	etags    map[string]string // File path, variants included, to ETag
+	index    map[string][]byte // index.html pointing at the hashed assets, by encoding
+	indexTag map[string]string
+}
+
+// newAssetServer hash the embedded files once. Without manifest the assets are served under their own names
+func newAssetServer(files fs.FS, log *logger.Logger) (*assetServer, error) {
+	manifest, err := assets.LoadManifest(files)
+	if err != nil {
+		log.Debug("no asset manifest, serving unhashed assets", "err", err)
+		manifest = assets.Manifest{}
+	}
+
+	s := &assetServer{
+		files:    files,
+		manifest: manifest,
+		etags:    map[string]string{},
+		index:    map[string][]byte{},
+		indexTag: map[string]string{},
+	}
+
+	err = fs.WalkDir(files, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		content, err := fs.ReadFile(files, p)
+		if err != nil {
+			return err
+		}
+		s.etags[p] = etagOf(content)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.buildIndex(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// buildIndex rewrite index.html to the hashed names and compress it
+func (s *assetServer) buildIndex() error {
+	raw, err := fs.ReadFile(s.files, indexFile)
+	if err != nil {
+		return err
+	}
+
+	pairs := make([]string, 0, len(s.manifest)*2)
+	for name, hashed := range s.manifest {
+		pairs = append(pairs, name, hashed)
+	}
+	index := []byte(strings.NewReplacer(pairs...).Replace(string(raw)))
+
+	gz, br, err := assets.Compress(index)
+	if err != nil {
+		return err
+	}
+
+	s.index[""], s.index["gzip"], s.index["br"] = index, gz, br
+	for enc, content := range s.index {
+		s.indexTag[enc] = etagOf(content)
+	}
+
+	return nil
+}
+
+func (s *assetServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+	if name == "" || name == indexFile {
+		s.serveIndex(w, r)
+		return
+	}
+
+	h := w.Header()
+	h.Set("Cache-Control", cacheRevalidate)
+	if s.manifest.Hashed(name) {
+		h.Set("Cache-Control", cacheImmutable)
+	}
+
+	// Logical names are served from the hashed copy, with its variants, but revalidated
+	src := name
+	if hashed, ok := s.manifest[name]; ok {
+		src = hashed
+	}
+
+	if _, ok := s.etags[src]; !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	file, enc := s.negotiate(r, src)
+
+	h.Set("Content-Type", contentType(name))
+	h.Set("ETag", s.etags[file])
+	if enc != "" {
+		h.Set("Content-Encoding", enc)
+	}
+	if s.hasVariant(src) {
+		h.Add("Vary", "Accept-Encoding")
+	}
+
+	f, err := s.files.Open(file)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+
+	rs, ok := f.(io.ReadSeeker)
+	if !ok {
+		http.Error(w, "asset not seekable", http.StatusInternalServerError)
+		return
+	}
+
+	http.ServeContent(w, r, name, time.Time{}, rs)
+}
+
+func (s *assetServer) serveIndex(w http.ResponseWriter, r *http.Request) {
+	enc := ""
+	for _, e := range encodings {
+		if acceptsEncoding(r.Header.Get("Accept-Encoding"), e.name) {
+			enc = e.name
+			break
+		}
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", "text/html; charset=utf-8")
+	h.Set("ETag", s.indexTag[enc])
+	h.Set("Cache-Control", cacheRevalidate)
+	h.Add("Vary", "Accept-Encoding")
+	if enc != "" {
+		h.Set("Content-Encoding", enc)
+	}
+
+	http.ServeContent(w, r, indexFile, time.Time{}, bytes.NewReader(s.index[enc]))
+}
+
+// negotiate pick the precompressed variant accepted by the client, if any
+func (s *assetServer) negotiate(r *http.Request, name string) (string, string) {
+	accept := r.Header.Get("Accept-Encoding")
+	for _, e := range encodings {
+		if _, ok := s.etags[name+e.ext]; ok && acceptsEncoding(accept, e.name) {
+			return name + e.ext, e.name
+		}
+	}
+
+	return name, ""
+}
+
+func (s *assetServer) hasVariant(name string) bool {
+	for _, e := range encodings {
+		if _, ok := s.etags[name+e.ext]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsEncoding report whether the Accept-Encoding header lists enc, or *, with a non zero quality.
+// The enc entry wins over *
+func acceptsEncoding(header, enc string) bool {
+	star := false
+	for _, part := range strings.Split(header, ",") {
+		token, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		token = strings.TrimSpace(token)
+
+		accepted := true
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				accepted = false
+			}
+		}
+
+		switch {
+		case strings.EqualFold(token, enc):
+			return accepted
+		case token == "*":
+			star = accepted
+		}
+	}
+
+	return star
+}
+
+func contentType(name string) string {
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
+
+func etagOf(content []byte) string {
+	sum := sha256.Sum256(content)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
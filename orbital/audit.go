@@ -0,0 +1,145 @@
+package orbital
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Audit transports
+const (
+	AuditHTTP  = "http"
+	AuditBatch = "batch"
+	AuditWs    = "ws"
+	AuditAdmin = "admin" // Admin socket
+)
+
+// AuditRecord one action made on the node: an RPC call, whatever its transport, or a websocket command
+type AuditRecord struct {
+	Time       time.Time
+	PublicKey  string // Verified signer. Empty for public routes
+	UserID     string // Set for authenticated websocket connections and admin socket peers (uid:<n>). Resolved by the auditor otherwise
+	Service    string
+	Action     string
+	ParamsHash string // SHA-256 of the request body
+	Code       Code
+	RemoteAddr string
+	Transport  string
+	RequestID  string
+}
+
+// AuditFunc store a record. Called once the action replied
+type AuditFunc func(ctx context.Context, record AuditRecord)
+
+// SetAuditor record every RPC call served
+func (s *Server) SetAuditor(audit AuditFunc) {
+	s.audit = audit
+}
+
+// SetAuditor record the websocket commands: authenticate, subscriptions and topics with a permission
+func (ws *WsConn) SetAuditor(audit AuditFunc) {
+	ws.audit = audit
+}
+
+// auditMiddleware record the call once served. A no-op until an auditor is set
+func (s *Server) auditMiddleware() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if s.audit == nil {
+				next(w, r)
+				return
+			}
+
+			start := time.Now()
+			next(w, r)
+
+			ctx := r.Context()
+			state := requestStateOf(ctx)
+
+			code, set := state.replyCode()
+			if sr, ok := w.(*statusRecorder); ok && !set && sr.status >= http.StatusBadRequest {
+				code = Internal
+			}
+
+			publicKey, body := state.caller(ctx)
+			service, action := routeOf(r.URL.Path)
+
+			var userID string
+			transport := AuditHTTP
+			switch peer, local := LocalAdmin(ctx); {
+			case local:
+				transport = AuditAdmin
+				userID = "uid:" + strconv.FormatUint(uint64(peer.UID), 10)
+			case InBatch(ctx):
+				transport = AuditBatch
+			case ConnID(ctx) != "":
+				transport = AuditWs
+			}
+
+			s.audit(ctx, AuditRecord{
+				Time:       start,
+				PublicKey:  publicKey,
+				UserID:     userID,
+				Service:    service,
+				Action:     action,
+				ParamsHash: paramsHash(body),
+				Code:       code,
+				RemoteAddr: r.RemoteAddr,
+				Transport:  transport,
+				RequestID:  CorrelationID(ctx),
+			})
+		}
+	}
+}
+
+// auditCommand record a websocket command handled by handleMessage
+func (ws *WsConn) auditCommand(ctx context.Context, conn *WsConnection, domain, action, envelopeKey string, body []byte, start time.Time) {
+	if ws.audit == nil {
+		return
+	}
+
+	code, _ := requestStateOf(ctx).replyCode()
+
+	record := AuditRecord{
+		Time:       start,
+		PublicKey:  envelopeKey,
+		Service:    domain,
+		Action:     action,
+		ParamsHash: paramsHash(body),
+		Code:       code,
+		RemoteAddr: conn.RemoteAddr,
+		Transport:  AuditWs,
+		RequestID:  CorrelationID(ctx),
+	}
+
+	// The key bound by system/authenticate wins over the one claimed by the envelope
+	if identity := ws.connectionManager.Identity(conn.ID); identity != nil {
+		record.PublicKey = ws.connectionManager.PublicKey(conn.ID)
+		record.UserID = identity.UserID
+	}
+
+	ws.audit(ctx, record)
+}
+
+// routeOf split /rpc/<service>/<action>
+func routeOf(routePath string) (string, string) {
+	service, action, found := strings.Cut(strings.TrimPrefix(routePath, "/"+rpcDomain+"/"), "/")
+	if !found {
+		return rpcDomain, service
+	}
+
+	return service, action
+}
+
+func paramsHash(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
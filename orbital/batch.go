@@ -0,0 +1,198 @@
+package orbital
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"orbital/pkg/cryptographer"
+	"path"
+	"sync"
+)
+
+// maxBatchCalls upper bound of the calls in one batch
+const maxBatchCalls = 32
+
+type batchCtxKeyType struct{}
+
+// batchCtxKey marks a call made from a batch. Its envelope was verified by the batch
+var batchCtxKey = batchCtxKeyType{}
+
+type (
+	// BatchCall one route call of a batch
+	BatchCall struct {
+		Service string          `json:"service"`
+		Action  string          `json:"action"`
+		Body    json.RawMessage `json:"body,omitempty"`
+	}
+
+	// BatchReq body of the /rpc/batch envelope
+	BatchReq struct {
+		Calls    []BatchCall `json:"calls"`
+		Parallel bool        `json:"parallel,omitempty"`
+	}
+
+	// BatchResult reply of one call, in the order of the request. Body is the route reply or an ErrorReply
+	BatchResult struct {
+		Code Code            `json:"code"`
+		Body json.RawMessage `json:"body"`
+	}
+
+	BatchResp struct {
+		Results []BatchResult  `json:"results"`
+		Code    Code           `json:"code"`
+		Error   *ErrorResponse `json:"error,omitempty"`
+	}
+)
+
+// InBatch report whether the request is a call of a verified batch.
+// The call body and signer public key are already in the context
+func InBatch(ctx context.Context) bool {
+	inBatch, _ := ctx.Value(batchCtxKey).(bool)
+	return inBatch
+}
+
+// registerBatch add the /rpc/batch route
+func (s *Server) registerBatch() {
+	s.Register(Route{
+		ActionName:  "batch",
+		Handler:     s.handleBatch,
+		Method:      http.MethodPost,
+		Description: "Run several calls with one signed envelope. Each call is checked against its route permission",
+		Request:     BatchReq{},
+		Response:    BatchResp{},
+	})
+}
+
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	publicKey, body, err := s.batchCaller(r)
+	if err != nil {
+		s.OnError(w, r, err)
+		return
+	}
+
+	var req BatchReq
+	if err = json.Unmarshal(body, &req); err != nil {
+		s.OnError(w, r, fmt.Errorf("%w:[%v]", ErrUnmarshalPayload, err))
+		return
+	}
+
+	if len(req.Calls) == 0 || len(req.Calls) > maxBatchCalls {
+		s.OnError(w, r, NewError(InvalidRequest, "rpc.badBatchSize", "invalid number of calls").
+			WithDetails(map[string]any{"max": maxBatchCalls}))
+		return
+	}
+
+	ctx := WithCaller(context.WithValue(r.Context(), batchCtxKey, true), publicKey, body)
+
+	results := make([]BatchResult, len(req.Calls))
+	if req.Parallel {
+		var wg sync.WaitGroup
+		for i, call := range req.Calls {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i] = s.batchCall(ctx, r, call)
+			}()
+		}
+		wg.Wait()
+	} else {
+		for i, call := range req.Calls {
+			results[i] = s.batchCall(ctx, r, call)
+		}
+	}
+
+	s.Reply(w, r, cryptographer.Metadata{
+		Domain: rpcDomain,
+		Action: "batch",
+	}, BatchResp{
+		Results: results,
+		Code:    OK,
+	})
+}
+
+// batchCaller verify the envelope and return its signer and body.
+// Admin socket peers send the bare body
+func (s *Server) batchCaller(r *http.Request) (string, []byte, error) {
+	if _, local := LocalAdmin(r.Context()); local {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w:[%v]", ErrBadPayload, err)
+		}
+		return "", body, nil
+	}
+
+	var msg cryptographer.Message
+	if err := Decode(r.Body, &msg); err != nil {
+		return "", nil, NewError(InvalidRequest, "rpc.badEnvelope", "bad JSON envelope").WithCause(err)
+	}
+
+	valid, err := msg.Verify()
+	if err != nil || !valid {
+		RecordSignatureFailure("batch")
+		return "", nil, NewError(Unauthenticated, "rpc.badSignature", "invalid envelope signature")
+	}
+
+	return hex.EncodeToString(msg.PublicKey[:]), msg.Body, nil
+}
+
+// batchCall run one call through its route middlewares and keep the unwrapped reply body
+func (s *Server) batchCall(ctx context.Context, r *http.Request, call BatchCall) BatchResult {
+	if call.Service == "" || call.Action == "" {
+		return batchError(NewError(InvalidRequest, "rpc.badBatchCall", "service and action are required"))
+	}
+
+	// Every call of the batch counts against the key budget
+	publicKey, _ := ctx.Value(cryptographer.PublicKeyCtxKey).(string)
+	if err := s.AllowKey(publicKey); err != nil {
+		return batchError(err)
+	}
+
+	routePath := path.Clean(fmt.Sprintf("/rpc/%s/%s", call.Service, call.Action))
+	cr, found := s.routes[routePath]
+	if !found || cr.route.ServiceName == "" {
+		return batchError(NewError(NotFound, "rpc.routeNotFound", "route not found").
+			WithDetails(map[string]any{"path": routePath}))
+	}
+
+	body := []byte(call.Body)
+	if body == nil {
+		body = []byte{}
+	}
+
+	subReq, err := http.NewRequestWithContext(context.WithValue(ctx, cryptographer.BodyCtxKey, body), http.MethodPost, routePath, http.NoBody)
+	if err != nil {
+		return batchError(err)
+	}
+	subReq.RemoteAddr = r.RemoteAddr
+
+	rec := &responseRecorder{header: make(http.Header)}
+	cr.handler.ServeHTTP(rec, subReq)
+
+	var reply cryptographer.Message
+	if err = json.Unmarshal(rec.body.Bytes(), &reply); err != nil {
+		return batchError(NewError(Internal, "rpc.batchCallFailed", "call did not reply with an envelope").WithCause(err))
+	}
+
+	var status struct {
+		Code Code `json:"code"`
+	}
+	_ = json.Unmarshal(reply.Body, &status)
+
+	return BatchResult{
+		Code: status.Code,
+		Body: reply.Body,
+	}
+}
+
+func batchError(err error) BatchResult {
+	e := AsError(err)
+	body, _ := json.Marshal(e.Reply())
+
+	return BatchResult{
+		Code: e.Code,
+		Body: body,
+	}
+}
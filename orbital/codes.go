@@ -1,17 +1,88 @@
 package orbital
 
+import "net/http"
+
 type Code uint32
 
 const (
-	OK              Code = 0
-	Canceled        Code = 1
-	Unknown         Code = 2
-	NotFound        Code = 3
-	Unimplemented   Code = 4
-	Unauthenticated Code = 5
-	Internal        Code = 6
-	Unavailable     Code = 7
-	InvalidRequest  Code = 8
+	OK                 Code = 0
+	Canceled           Code = 1
+	Unknown            Code = 2
+	NotFound           Code = 3
+	Unimplemented      Code = 4
+	Unauthenticated    Code = 5
+	Internal           Code = 6
+	Unavailable        Code = 7
+	InvalidRequest     Code = 8
+	PermissionDenied   Code = 9
+	ResourceExhausted  Code = 10
+	DeadlineExceeded   Code = 11
+	AlreadyExists      Code = 12
+	MethodNotAllowed   Code = 13
+	FailedPrecondition Code = 14
 
 	// TODO: Add more codes as needed
 )
+
+var codeNames = map[Code]string{
+	OK:                 "ok",
+	Canceled:           "canceled",
+	Unknown:            "unknown",
+	NotFound:           "notFound",
+	Unimplemented:      "unimplemented",
+	Unauthenticated:    "unauthenticated",
+	Internal:           "internal",
+	Unavailable:        "unavailable",
+	InvalidRequest:     "invalidRequest",
+	PermissionDenied:   "permissionDenied",
+	ResourceExhausted:  "resourceExhausted",
+	DeadlineExceeded:   "deadlineExceeded",
+	AlreadyExists:      "alreadyExists",
+	MethodNotAllowed:   "methodNotAllowed",
+	FailedPrecondition: "failedPrecondition",
+}
+
+var codeStatuses = map[Code]int{
+	OK:                 http.StatusOK,
+	Canceled:           499, // Client closed request. No constant in net/http
+	Unknown:            http.StatusInternalServerError,
+	NotFound:           http.StatusNotFound,
+	Unimplemented:      http.StatusNotImplemented,
+	Unauthenticated:    http.StatusUnauthorized,
+	Internal:           http.StatusInternalServerError,
+	Unavailable:        http.StatusServiceUnavailable,
+	InvalidRequest:     http.StatusBadRequest,
+	PermissionDenied:   http.StatusForbidden,
+	ResourceExhausted:  http.StatusTooManyRequests,
+	DeadlineExceeded:   http.StatusGatewayTimeout,
+	AlreadyExists:      http.StatusConflict,
+	MethodNotAllowed:   http.StatusMethodNotAllowed,
+	FailedPrecondition: http.StatusBadRequest, // The request is valid, the state it runs in is not
+}
+
+func (c Code) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+
+	return codeNames[Unknown]
+}
+
+// HTTPStatus maps the code to the HTTP status used on error responses
+func (c Code) HTTPStatus() int {
+	if status, ok := codeStatuses[c]; ok {
+		return status
+	}
+
+	return http.StatusInternalServerError
+}
+
+// Retryable report if a request failing with this code can be safely retried as is
+func (c Code) Retryable() bool {
+	switch c {
+	case Unavailable, ResourceExhausted, DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,125 @@
+package orbital
+
+import (
+	"net/http"
+	"net/url"
+	"orbital/config"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// corsExposedHeaders response headers readable by allowed cross origin callers
+const corsExposedHeaders = "X-Request-Id, Retry-After"
+
+// corsPolicy origin policy of the browser requests. Patterns match the Origin host like websocket.AcceptOptions.OriginPatterns
+type corsPolicy struct {
+	patterns    []string
+	headers     string
+	methods     string
+	credentials bool
+	maxAge      string
+}
+
+// newCORSPolicy build the policy from the config section. Nil allows the same origin only
+func newCORSPolicy(cfg *config.CORS) *corsPolicy {
+	c := config.DefaultCORS()
+	if cfg != nil {
+		c.AllowedOrigins = cfg.AllowedOrigins
+		c.AllowCredentials = cfg.AllowCredentials
+		if len(cfg.AllowedHeaders) > 0 {
+			c.AllowedHeaders = cfg.AllowedHeaders
+		}
+		if len(cfg.AllowedMethods) > 0 {
+			c.AllowedMethods = cfg.AllowedMethods
+		}
+		if cfg.MaxAge > 0 {
+			c.MaxAge = cfg.MaxAge
+		}
+	}
+
+	return &corsPolicy{
+		patterns:    c.AllowedOrigins,
+		headers:     strings.Join(c.AllowedHeaders, ", "),
+		methods:     strings.Join(c.AllowedMethods, ", "),
+		credentials: c.AllowCredentials,
+		maxAge:      strconv.Itoa(c.MaxAge),
+	}
+}
+
+// allowed report whether the Origin is the node itself or matches a pattern.
+// Requests without Origin are not made by a browser on behalf of another site
+func (p *corsPolicy) allowed(r *http.Request, origin string) bool {
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	host := strings.ToLower(u.Host)
+	for _, pattern := range p.patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), host); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// corsMiddleware reject the requests of disallowed origins and answer the preflights of allowed ones.
+// The policy is loaded on every request so a reload applies to the next one
+func corsMiddleware(policy *atomic.Pointer[corsPolicy], next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := policy.Load()
+
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+
+		if !p.allowed(r, origin) {
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+
+		h := w.Header()
+		h.Set("Access-Control-Allow-Origin", origin)
+		h.Set("Access-Control-Expose-Headers", corsExposedHeaders)
+		if p.credentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			h.Set("Access-Control-Allow-Methods", p.methods)
+			h.Set("Access-Control-Allow-Headers", p.headers)
+			h.Set("Access-Control-Max-Age", p.maxAge)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SetOriginPatterns origins allowed to open a websocket besides the node one. See config.CORS
+func (ws *WsConn) SetOriginPatterns(patterns []string) {
+	ws.originPatterns.Store(&patterns)
+}
+
+func (ws *WsConn) originPatternsList() []string {
+	if patterns := ws.originPatterns.Load(); patterns != nil {
+		return *patterns
+	}
+	return nil
+}
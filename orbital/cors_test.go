@@ -0,0 +1,203 @@
+package orbital
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"orbital/config"
+	"orbital/pkg/logger"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/coder/websocket"
+)
+
+func newTestCORS(cfg *config.CORS) http.Handler {
+	var policy atomic.Pointer[corsPolicy]
+	policy.Store(newCORSPolicy(cfg))
+
+	return corsMiddleware(&policy, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func corsRequest(h http.Handler, method, origin string, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "http://node.local:8080/rpc", nil)
+	for key, values := range header {
+		r.Header[key] = values
+	}
+	if origin != "" {
+		r.Header.Set("Origin", origin)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestCORSSameOriginDefault(t *testing.T) {
+	h := newTestCORS(nil)
+
+	tests := []struct {
+		name   string
+		origin string
+		status int
+	}{
+		{"no origin", "", http.StatusOK},
+		{"same origin", "http://node.local:8080", http.StatusOK},
+		{"same origin other case", "http://NODE.local:8080", http.StatusOK},
+		{"other port", "http://node.local:9090", http.StatusForbidden},
+		{"foreign origin", "https://evil.example", http.StatusForbidden},
+		{"opaque origin", "null", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := corsRequest(h, http.MethodPost, tt.origin, nil)
+			if w.Code != tt.status {
+				t.Fatalf("want %d, got %d", tt.status, w.Code)
+			}
+			if tt.status == http.StatusForbidden && w.Header().Get("Access-Control-Allow-Origin") != "" {
+				t.Fatal("denied origin got Access-Control-Allow-Origin")
+			}
+		})
+	}
+}
+
+func TestCORSPatterns(t *testing.T) {
+	h := newTestCORS(&config.CORS{AllowedOrigins: []string{"*.example.com", "localhost:*"}})
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://app.example.com", true},
+		{"https://APP.Example.com", true},
+		{"https://example.com", false},
+		{"https://a.b.example.com", true}, // path.Match stars span dots, as websocket OriginPatterns
+		{"https://app.example.com.evil.io", false},
+		{"http://localhost:3000", true},
+		{"http://localhost", false},
+		{"http://127.0.0.1:3000", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			w := corsRequest(h, http.MethodPost, tt.origin, nil)
+
+			if !tt.allowed {
+				if w.Code != http.StatusForbidden {
+					t.Fatalf("want 403, got %d", w.Code)
+				}
+				return
+			}
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("want 200, got %d", w.Code)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.origin {
+				t.Fatalf("Access-Control-Allow-Origin %q, want %q", got, tt.origin)
+			}
+			if got := w.Header().Get("Access-Control-Expose-Headers"); got != corsExposedHeaders {
+				t.Fatalf("Access-Control-Expose-Headers %q", got)
+			}
+			if got := w.Header().Get("Vary"); got != "Origin" {
+				t.Fatalf("Vary %q, want Origin", got)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+				t.Fatalf("credentials allowed without allowCredentials: %q", got)
+			}
+		})
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	h := newTestCORS(&config.CORS{
+		AllowedOrigins:   []string{"app.example.com"},
+		AllowedMethods:   []string{"POST"},
+		AllowCredentials: true,
+		MaxAge:           60,
+	})
+
+	preflight := http.Header{"Access-Control-Request-Method": {"POST"}}
+
+	w := corsRequest(h, http.MethodOptions, "https://app.example.com", preflight)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("want 204, got %d", w.Code)
+	}
+
+	want := map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Methods":     "POST",
+		"Access-Control-Allow-Headers":     "Content-Type, Authorization, Accept-Encoding, X-Request-Id, Traceparent",
+		"Access-Control-Max-Age":           "60",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Expose-Headers":    corsExposedHeaders,
+		"Vary":                             "Origin",
+	}
+	for key, value := range want {
+		if got := w.Header().Get(key); got != value {
+			t.Errorf("%s %q, want %q", key, got, value)
+		}
+	}
+
+	// A plain OPTIONS is not a preflight, it reaches the handler
+	if w = corsRequest(h, http.MethodOptions, "https://app.example.com", nil); w.Code != http.StatusOK {
+		t.Fatalf("plain OPTIONS want 200, got %d", w.Code)
+	}
+
+	// Preflights of a denied origin get no CORS headers
+	w = corsRequest(h, http.MethodOptions, "https://evil.example", preflight)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("denied preflight want 403, got %d", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != "" {
+		t.Fatalf("denied preflight got Access-Control-Allow-Methods %q", got)
+	}
+}
+
+func TestWsOriginPatterns(t *testing.T) {
+	ws := NewWsConn(logger.New(logger.LevelError, logger.FormatString))
+	if got := ws.originPatternsList(); got != nil {
+		t.Fatalf("want no patterns by default, got %v", got)
+	}
+
+	patterns := []string{"app.example.com"}
+	ws.SetOriginPatterns(patterns)
+	if got := ws.originPatternsList(); !slices.Equal(got, patterns) {
+		t.Fatalf("patterns %v, want %v", got, patterns)
+	}
+
+	srv := httptest.NewServer(ws)
+	defer srv.Close()
+
+	dial := func(origin string) (*http.Response, error) {
+		conn, resp, err := websocket.Dial(context.Background(), srv.URL, &websocket.DialOptions{
+			HTTPHeader: http.Header{"Origin": {origin}},
+		})
+		if err == nil {
+			conn.CloseNow()
+		}
+		return resp, err
+	}
+
+	resp, err := dial("https://evil.example")
+	if err == nil {
+		t.Fatal("foreign origin accepted")
+	}
+	if resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("foreign origin want 403, got %v", resp)
+	}
+
+	if _, err = dial("https://app.example.com"); err != nil {
+		t.Fatalf("allowed origin rejected: %v", err)
+	}
+
+	// A reload replaces the patterns for the next handshakes
+	ws.SetOriginPatterns(nil)
+	if _, err = dial("https://app.example.com"); err == nil {
+		t.Fatal("origin still accepted after the patterns were cleared")
+	}
+}
@@ -0,0 +1,75 @@
+package orbital
+
+import (
+	"orbital/pkg/jsonschema"
+	"sort"
+)
+
+// RouteInfo public description of a registered HTTP route
+type RouteInfo struct {
+	Path        string             `json:"path"`
+	Service     string             `json:"service"`
+	Action      string             `json:"action"`
+	Method      string             `json:"method,omitempty"`
+	Permission  string             `json:"permission,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Request     *jsonschema.Schema `json:"request,omitempty"`
+	Response    *jsonschema.Schema `json:"response,omitempty"`
+
+	request, response any
+}
+
+// TopicInfo public description of a registered websocket topic
+type TopicInfo struct {
+	Name        string             `json:"name"`
+	Permission  string             `json:"permission,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Request     *jsonschema.Schema `json:"request,omitempty"`
+
+	request any
+}
+
+// Routes describe all registered routes sorted by path
+func (s *Server) Routes() []RouteInfo {
+	routes := make([]RouteInfo, 0, len(s.routes))
+	for routePath, cr := range s.routes {
+		routes = append(routes, RouteInfo{
+			Path:        routePath,
+			Service:     cr.route.ServiceName,
+			Action:      cr.route.ActionName,
+			Method:      cr.route.Method,
+			Permission:  cr.route.Permission,
+			Description: cr.route.Description,
+			Request:     jsonschema.Reflect(cr.route.Request),
+			Response:    jsonschema.Reflect(cr.route.Response),
+			request:     cr.route.Request,
+			response:    cr.route.Response,
+		})
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].Path < routes[j].Path
+	})
+
+	return routes
+}
+
+// Topics describe all registered topics sorted by name
+func (ws *WsConn) Topics() []TopicInfo {
+	topics := make([]TopicInfo, 0, len(ws.topics))
+	for _, t := range ws.topics {
+		topics = append(topics, TopicInfo{
+			Name:        t.Name,
+			Permission:  t.Permission,
+			Description: t.Description,
+			Request:     jsonschema.Reflect(t.Request),
+			request:     t.Request,
+		})
+	}
+
+	sort.Slice(topics, func(i, j int) bool {
+		return topics[i].Name < topics[j].Name
+	})
+
+	return topics
+}
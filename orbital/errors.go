@@ -1,31 +1,163 @@
 package orbital
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"time"
+)
 
 var (
 	ErrBadPayload       = errors.New("unable to decode payload")
 	ErrUnmarshalPayload = errors.New("unable to unmarshal payload")
 	ErrPathNotFound     = errors.New("path not found")
+	ErrMethodNotAllowed = errors.New("method not allowed")
 	ErrHttpListen       = errors.New("http listen error")
+	ErrNotListening     = errors.New("http listener is not serving")
+	ErrSignerMissing    = errors.New("node signer not set")
+	ErrConnNotFound     = errors.New("connection not found")
+	ErrConnClosed       = errors.New("connection closed")
+	ErrSendQueueFull    = errors.New("send queue full")
+	ErrAdminListen      = errors.New("admin socket listen error")
+	ErrAdminSocketInUse = errors.New("admin socket in use by another node")
+	ErrPeerCred         = errors.New("cannot read peer credentials")
+	ErrTLSCertificate   = errors.New("cannot load tls certificate")
+	ErrReloadInvalid    = errors.New("config not reloaded")
 )
 
+// Error typed error returned by services.
+// It maps to an HTTP status and is sent to the client as ErrorReply
 type Error struct {
-	Code Code        `json:"code"`
-	Msg  interface{} `json:"msg"`
+	Code      Code           `json:"code"`
+	Type      string         `json:"type"`
+	Msg       string         `json:"msg"`
+	Details   map[string]any `json:"details,omitempty"`
+	Retryable bool           `json:"retryable"`
+	cause     error
+	retry     time.Duration // Sent as Retry-After over HTTP
+}
+
+// NewError create a typed error. Retryable defaults to what the code allows
+func NewError(code Code, errType, msg string) *Error {
+	return &Error{
+		Code:      code,
+		Type:      errType,
+		Msg:       msg,
+		Retryable: code.Retryable(),
+	}
 }
 
 func (e *Error) Error() string {
-	switch msg := e.Msg.(type) {
-	case string:
-		return msg
-	case error:
-		return msg.Error()
+	if e.Msg != "" {
+		return e.Msg
+	}
+
+	return e.Code.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// WithDetails attach extra details visible to the client
+func (e *Error) WithDetails(details map[string]any) *Error {
+	e.Details = details
+	return e
+}
+
+// WithRetryable override the retryable flag set from the code
+func (e *Error) WithRetryable(retryable bool) *Error {
+	e.Retryable = retryable
+	return e
+}
+
+// WithRetryAfter tell the client when to retry. Added to the details as retryAfterMs
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	if e.Details == nil {
+		e.Details = make(map[string]any)
+	}
+	e.Details["retryAfterMs"] = d.Milliseconds()
+	e.Retryable = true
+	e.retry = d
+
+	return e
+}
+
+// WithCause keep the original error for logging. It is never sent to the client
+func (e *Error) WithCause(err error) *Error {
+	e.cause = err
+	return e
+}
+
+// logCause return the error worth logging: the wrapped cause if any, otherwise the original one
+func (e *Error) logCause(original error) error {
+	if e.cause != nil {
+		return e.cause
+	}
+
+	return original
+}
+
+// Response convert the error to its wire representation
+func (e *Error) Response() *ErrorResponse {
+	return &ErrorResponse{
+		Type:      e.Type,
+		Msg:       e.Msg,
+		Details:   e.Details,
+		Retryable: e.Retryable,
+	}
+}
+
+// Reply convert the error to a response body
+func (e *Error) Reply() ErrorReply {
+	return ErrorReply{
+		Code:  e.Code,
+		Error: e.Response(),
+	}
+}
+
+// AsError convert any error to a typed Error.
+// Unknown errors become Internal and their message is hidden from the client
+func AsError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	switch {
+	case errors.Is(err, ErrBadPayload), errors.Is(err, ErrUnmarshalPayload):
+		return NewError(InvalidRequest, "orbital.invalidRequest", err.Error()).WithCause(err)
+	case errors.Is(err, ErrPathNotFound):
+		return NewError(NotFound, "orbital.notFound", err.Error()).WithCause(err)
+	case errors.Is(err, ErrMethodNotAllowed):
+		return NewError(MethodNotAllowed, "orbital.methodNotAllowed", err.Error()).WithCause(err)
+	case errors.Is(err, ErrReloadInvalid):
+		return NewError(InvalidRequest, "config.invalid", err.Error()).WithCause(err)
+	case errors.Is(err, ErrSendQueueFull):
+		return NewError(ResourceExhausted, "orbital.sendQueueFull", err.Error()).WithCause(err)
+	case errors.Is(err, context.Canceled):
+		return NewError(Canceled, "orbital.canceled", "request canceled").WithCause(err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return NewError(DeadlineExceeded, "orbital.deadlineExceeded", "request deadline exceeded").WithCause(err)
 	default:
-		return "Unknown error"
+		return NewError(Internal, "orbital.internal", "internal error").WithCause(err)
 	}
 }
 
+// ErrorResponse wire representation of an Error.
+// Embedded in every response body as the `error` field
 type ErrorResponse struct {
-	Type string `json:"type"`
-	Msg  string `json:"msg"`
+	Type      string         `json:"type"`
+	Msg       string         `json:"msg"`
+	Details   map[string]any `json:"details,omitempty"`
+	Retryable bool           `json:"retryable,omitempty"`
+}
+
+// ErrorReply body sent when a request fails
+type ErrorReply struct {
+	Code  Code           `json:"code"`
+	Error *ErrorResponse `json:"error"`
 }
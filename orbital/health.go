@@ -0,0 +1,104 @@
+package orbital
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"orbital/pkg/buildinfo"
+	"time"
+)
+
+// healthTimeout budget of one check
+const healthTimeout = 2 * time.Second
+
+// HealthCheck probe reported by /readyz. Liveness checks are reported by /healthz too
+type HealthCheck struct {
+	Name     string
+	Liveness bool
+	Check    func(ctx context.Context) error
+}
+
+type CheckResult struct {
+	Name       string `json:"name"`
+	OK         bool   `json:"ok"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+type HealthResp struct {
+	OK            bool          `json:"ok"`
+	Checks        []CheckResult `json:"checks"`
+	UptimeSeconds int64         `json:"uptimeSeconds"`
+}
+
+// AddHealthCheck register a probe. The listener state is always checked
+func (n *Orbital) AddHealthCheck(check HealthCheck) {
+	n.checks = append(n.checks, check)
+}
+
+// healthHandler run the checks and reply 200 when all pass, 503 otherwise.
+// Unauthenticated and unsigned so load balancers and watchdogs can use it
+func (n *Orbital) healthHandler(livenessOnly bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		checks := append([]HealthCheck{{
+			Name:     "listener",
+			Liveness: true,
+			Check: func(context.Context) error {
+				if !n.listening.Load() {
+					return ErrNotListening
+				}
+				return nil
+			},
+		}}, n.checks...)
+
+		resp := HealthResp{
+			OK:            true,
+			UptimeSeconds: int64(buildinfo.Uptime().Seconds()),
+		}
+
+		for _, c := range checks {
+			if livenessOnly && !c.Liveness {
+				continue
+			}
+
+			res := runCheck(r.Context(), c)
+			resp.OK = resp.OK && res.OK
+			resp.Checks = append(resp.Checks, res)
+		}
+
+		status := http.StatusOK
+		if !resp.OK {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func runCheck(ctx context.Context, c HealthCheck) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.Check(ctx)
+
+	res := CheckResult{
+		Name:       c.Name,
+		OK:         err == nil,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		res.Error = err.Error()
+	}
+
+	return res
+}
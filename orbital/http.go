@@ -2,6 +2,7 @@ package orbital
 
 import (
 	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -18,84 +19,277 @@ type Route struct {
 	ServiceName string
 	ActionName  string
 	Handler     http.HandlerFunc
-	Method      string
+	Method      string       // Allowed HTTP method. Empty allows any method
+	Middlewares []Middleware // Route level middlewares. Executed after the server and group ones
+
+	// Introspection only. Used to describe the route, never to decode requests
+	Permission  string // Permission required to call the route
+	Description string
+	Request     any // Zero value of the request body type
+	Response    any // Zero value of the response body type
 }
 
 type HTTPService interface {
-	SetSecretKey(secretKey cryptographer.PrivateKey)
+	SetSigner(signer cryptographer.Signer)
 	Register(route Route)
+	Group(serviceName string, mw ...Middleware) *RouteGroup
 	OnError(w http.ResponseWriter, r *http.Request, err error)
+	Reply(w http.ResponseWriter, r *http.Request, meta cryptographer.Metadata, body any)
 	Use(mw ...Middleware)
+	SetAuthorizer(authorize AuthorizeFunc)
+	Authorize(ctx context.Context, publicKey, permission string) error
+	SetRateLimiter(limiter *RateLimiter)
+	AllowKey(publicKey string) error
+	SetAuditor(audit AuditFunc)
+	Routes() []RouteInfo
 	ServeHTTP(w http.ResponseWriter, r *http.Request)
 }
 
-type Server struct {
-	secretKey   cryptographer.PrivateKey
-	log         *logger.Logger
-	routes      map[string]Route
-	notFound    http.HandlerFunc
-	onError     func(w http.ResponseWriter, r *http.Request, err error)
+// RouteGroup register routes sharing the same service name and middlewares
+type RouteGroup struct {
+	server      HTTPService
+	serviceName string
 	middlewares []Middleware
 }
 
+// Use append middlewares to the group. Only routes registered afterward are affected
+func (g *RouteGroup) Use(mw ...Middleware) {
+	g.middlewares = append(g.middlewares, mw...)
+}
+
+// Group create a nested group inheriting the service name and middlewares
+func (g *RouteGroup) Group(mw ...Middleware) *RouteGroup {
+	return &RouteGroup{
+		server:      g.server,
+		serviceName: g.serviceName,
+		middlewares: chainOf(g.middlewares, mw),
+	}
+}
+
+// Register a route within the group.
+// Group middlewares are executed before the route ones
+func (g *RouteGroup) Register(route Route) {
+	if route.ServiceName == "" {
+		route.ServiceName = g.serviceName
+	}
+
+	route.Middlewares = chainOf(g.middlewares, route.Middlewares)
+	g.server.Register(route)
+}
+
+type compiledRoute struct {
+	route   Route
+	handler http.HandlerFunc
+}
+
+type Server struct {
+	signer           cryptographer.Signer
+	log              *logger.Logger
+	routes           map[string]*compiledRoute
+	notFound         http.HandlerFunc
+	methodNotAllowed http.HandlerFunc
+	onError          func(w http.ResponseWriter, r *http.Request, err error)
+	middlewares      []Middleware
+	authorize        AuthorizeFunc
+	limiter          *RateLimiter
+	audit            AuditFunc
+}
+
 func NewServer(log *logger.Logger) *Server {
 
 	srv := &Server{
 		log:         log,
-		routes:      make(map[string]Route),
-		notFound:    onNotFoundHandler,
-		onError:     onErrorHandler,
+		routes:      make(map[string]*compiledRoute),
 		middlewares: []Middleware{},
 	}
 
+	srv.onError = srv.writeError
+	srv.notFound = func(w http.ResponseWriter, r *http.Request) {
+		srv.OnError(w, r, ErrPathNotFound)
+	}
+	srv.methodNotAllowed = func(w http.ResponseWriter, r *http.Request) {
+		srv.OnError(w, r, ErrMethodNotAllowed)
+	}
+
 	srv.Use(
+		MetricsMiddleware(),
+		TraceMiddleware(),
+		srv.auditMiddleware(),
 		LoggerMiddleware(log),
 		PanicRecoverMiddleware(),
 	)
 
+	srv.registerBatch()
+
 	return srv
 }
 
-func (s *Server) SetSecretKey(secretKey cryptographer.PrivateKey) {
-	s.secretKey = secretKey
+func (s *Server) SetSigner(signer cryptographer.Signer) {
+	s.signer = signer
 }
 
+// Use append server level middlewares. These are executed for every route,
+// including the ones registered before the call
 func (s *Server) Use(mw ...Middleware) {
 	s.middlewares = append(s.middlewares, mw...)
+
+	for _, cr := range s.routes {
+		cr.handler = s.compile(cr.route)
+	}
+}
+
+// Group create a route group for a service
+func (s *Server) Group(serviceName string, mw ...Middleware) *RouteGroup {
+	return &RouteGroup{
+		server:      s,
+		serviceName: serviceName,
+		middlewares: chainOf(nil, mw),
+	}
 }
 
 func (s *Server) Register(route Route) {
 	routePath := path.Clean(fmt.Sprintf("/rpc/%s/%s", route.ServiceName, route.ActionName))
-	s.log.Info("Register", "path", routePath)
+	s.log.Info("Register", "path", routePath, "method", route.Method)
 	if _, found := s.routes[routePath]; found {
-		s.log.Error("route not found", "route", route)
+		s.log.Error("route already registered", "path", routePath)
 		return
 	}
 
-	s.routes[routePath] = route
+	s.routes[routePath] = &compiledRoute{
+		route:   route,
+		handler: s.compile(route),
+	}
 }
 
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Get Route
-	route, ok := s.routes[r.URL.Path]
+	cr, ok := s.routes[r.URL.Path]
 	if !ok {
 		s.notFound.ServeHTTP(w, r)
 		return
 	}
 
-	handler := route.Handler
-	for i := len(s.middlewares) - 1; i >= 0; i-- {
-		handler = s.middlewares[i](handler)
-	}
-
-	handler.ServeHTTP(w, r)
+	cr.handler.ServeHTTP(w, r)
 }
 
 func (s *Server) OnError(w http.ResponseWriter, r *http.Request, err error) {
 	s.onError(w, r, err)
 }
 
+// Reply sign the body with the node signer and send it as a successful response
+func (s *Server) Reply(w http.ResponseWriter, r *http.Request, meta cryptographer.Metadata, body any) {
+	if s.signer == nil {
+		s.OnError(w, r, ErrSignerMissing)
+		return
+	}
+
+	// Calls made over the websocket are matched by the client using the correlation id.
+	// HTTP replies carry the request id
+	if cid := CorrelationID(r.Context()); cid != "" {
+		meta.CorrelationID = cid
+	}
+
+	msg, err := cryptographer.Encode(s.signer, meta, body)
+	if err != nil {
+		s.OnError(w, r, err)
+		return
+	}
+
+	if err = Encode(w, r, http.StatusOK, msg); err != nil {
+		s.log.ErrorContext(r.Context(), "cannot write reply", "path", r.URL.Path, "err", err)
+	}
+}
+
+// compile build the middleware chain for a route.
+// Order: server middlewares -> method check -> route middlewares -> handler
+func (s *Server) compile(route Route) http.HandlerFunc {
+	handler := wrap(route.Handler, route.Middlewares)
+	handler = withPermission(route.Permission, handler)
+	handler = s.methodGuard(route.Method, handler)
+
+	return wrap(handler, s.middlewares)
+}
+
+func (s *Server) methodGuard(method string, next http.HandlerFunc) http.HandlerFunc {
+	if method == "" {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			s.methodNotAllowed.ServeHTTP(w, r)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// withPermission expose the route permission to the route middlewares
+func withPermission(permission string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		next(w, r.WithContext(context.WithValue(r.Context(), permissionCtxKey, permission)))
+	}
+}
+
+// RoutePermission return the permission of the route serving the request
+func RoutePermission(ctx context.Context) string {
+	permission, _ := ctx.Value(permissionCtxKey).(string)
+	return permission
+}
+
+// SetAuthorizer set the permission check used by Authorize
+func (s *Server) SetAuthorizer(authorize AuthorizeFunc) {
+	s.authorize = authorize
+}
+
+// Authorize check that the owner of the public key holds the permission. An empty permission is public
+// and admin socket peers hold every permission
+func (s *Server) Authorize(ctx context.Context, publicKey, permission string) error {
+	if permission == "" {
+		return nil
+	}
+
+	if _, local := LocalAdmin(ctx); local {
+		return nil
+	}
+
+	if s.authorize == nil {
+		return NewError(PermissionDenied, "orbital.permissionDenied", "permission denied")
+	}
+
+	return s.authorize(ctx, publicKey, permission)
+}
+
+// SetRateLimiter limit the requests per IP and route. Per key limits are applied by AllowKey
+func (s *Server) SetRateLimiter(limiter *RateLimiter) {
+	s.limiter = limiter
+	s.Use(limiter.Middleware(s))
+}
+
+// AllowKey take a token from the public key budget. Called once the envelope signature is verified
+func (s *Server) AllowKey(publicKey string) error {
+	return s.limiter.AllowKey(publicKey)
+}
+
+// wrap handler with middlewares. First middleware is the outermost
+func wrap(handler http.HandlerFunc, mws []Middleware) http.HandlerFunc {
+	for i := len(mws) - 1; i >= 0; i-- {
+		handler = mws[i](handler)
+	}
+
+	return handler
+}
+
+// chainOf return a new slice so groups never share the backing array
+func chainOf(base, extra []Middleware) []Middleware {
+	out := make([]Middleware, 0, len(base)+len(extra))
+	out = append(out, base...)
+	return append(out, extra...)
+}
+
 // Decode incoming http request body
 func Decode(r io.ReadCloser, data any) error {
 	bodyBytes, err := io.ReadAll(io.LimitReader(r, 1024*1024))
@@ -133,16 +327,29 @@ func Encode(w http.ResponseWriter, r *http.Request, status int, data any) error
 	return enc.Encode(data)
 }
 
-func onErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
-	_ = Encode(w, r, http.StatusInternalServerError, Error{
-		Internal,
-		err,
-	})
-}
+// writeError send a signed ErrorReply with the status mapped from the error code.
+// Falls back to an unsigned reply if the envelope cannot be signed
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	e := AsError(err)
+
+	s.log.ErrorContext(r.Context(), "request failed", "path", r.URL.Path, "code", e.Code.String(), "err", e.logCause(err))
+	setReplyCode(r.Context(), e.Code)
+
+	if e.retry > 0 {
+		w.Header().Set("Retry-After", retryAfterSeconds(e.retry))
+	}
+
+	reply := e.Reply()
+	msg, encErr := cryptographer.Encode(s.signer, cryptographer.Metadata{
+		Domain:        "system",
+		Action:        "error",
+		CorrelationID: CorrelationID(r.Context()),
+	}, reply)
+	if encErr != nil {
+		s.log.ErrorContext(r.Context(), "cannot sign error reply", "err", encErr)
+		_ = Encode(w, r, e.Code.HTTPStatus(), reply)
+		return
+	}
 
-func onNotFoundHandler(w http.ResponseWriter, r *http.Request) {
-	_ = Encode(w, r, http.StatusInternalServerError, Error{
-		NotFound,
-		ErrPathNotFound,
-	})
+	_ = Encode(w, r, e.Code.HTTPStatus(), msg)
 }
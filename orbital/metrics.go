@@ -0,0 +1,155 @@
+package orbital
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"orbital/config"
+	"orbital/pkg/metrics"
+	"strings"
+	"time"
+)
+
+var (
+	rpcRequests = metrics.NewCounter("orbital_rpc_requests_total",
+		"RPC requests by route and reply code", "route", "code")
+	rpcDuration = metrics.NewHistogram("orbital_rpc_request_duration_seconds",
+		"RPC handling latency by route", nil, "route")
+	wsConnections = metrics.NewGauge("orbital_ws_connections",
+		"Open websocket and SSE connections")
+	wsMessages = metrics.NewCounter("orbital_ws_messages_total",
+		"Stream messages by direction: in or out", "direction")
+	wsBytes = metrics.NewCounter("orbital_ws_bytes_total",
+		"Stream bytes by direction: in or out", "direction")
+	wsDropped = metrics.NewCounter("orbital_ws_dropped_total",
+		"Messages dropped because a send queue was full")
+	signatureFailures = metrics.NewCounter("orbital_signature_failures_total",
+		"Envelopes rejected by signature verification, by transport: http, batch or ws", "transport")
+)
+
+// RecordSignatureFailure count an envelope rejected by signature verification
+func RecordSignatureFailure(transport string) {
+	signatureFailures.Inc(transport)
+}
+
+// statusRecorder remember the HTTP status of the reply
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// MetricsMiddleware count the requests and their latency by route and reply code
+func MetricsMiddleware() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ctx, state := withRequestState(r.Context())
+			rec := &statusRecorder{ResponseWriter: w}
+
+			next(rec, r.WithContext(ctx))
+
+			code, set := state.replyCode()
+			if !set && rec.status >= http.StatusBadRequest {
+				code = Internal
+			}
+
+			route := r.URL.Path
+			rpcRequests.Inc(route, code.String())
+			rpcDuration.Since(start, route)
+		}
+	}
+}
+
+// MetricsHandler serve the default registry in the Prometheus text format.
+// Scrapers must present the bearer token or come from an allowed network.
+// Without a config only loopback scrapers are allowed
+func MetricsHandler(cfg *config.Metrics) http.Handler {
+	var (
+		token   string
+		allowed []*net.IPNet
+	)
+
+	if cfg != nil {
+		token = cfg.Token
+		allowed = parseNetworks(cfg.AllowIPs)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if !metricsAllowed(r, cfg, token, allowed) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = metrics.Default.WriteText(w)
+	})
+}
+
+func metricsAllowed(r *http.Request, cfg *config.Metrics, token string, allowed []*net.IPNet) bool {
+	if token != "" {
+		bearer, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if found && subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1 {
+			return true
+		}
+	}
+
+	ip := net.ParseIP(remoteIP(r.RemoteAddr))
+	if ip == nil {
+		return false
+	}
+
+	if cfg == nil {
+		return ip.IsLoopback()
+	}
+
+	for _, n := range allowed {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseNetworks accept CIDRs and single IPs. Invalid entries are skipped
+func parseNetworks(entries []string) []*net.IPNet {
+	var out []*net.IPNet
+	for _, e := range entries {
+		if !strings.Contains(e, "/") {
+			ip := net.ParseIP(e)
+			if ip == nil {
+				continue
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			out = append(out, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		if _, n, err := net.ParseCIDR(e); err == nil {
+			out = append(out, n)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,111 @@
+package orbital
+
+import (
+	"net/http"
+	"orbital/pkg/cryptographer"
+	"orbital/pkg/jsonschema"
+	"strings"
+)
+
+const openAPIVersion = "3.1.0"
+
+// OpenAPI build an OpenAPI document for the routes.
+// Requests and responses travel inside a signed envelope, so the operation content is the envelope
+// and the body schema is given by the x-orbital-request and x-orbital-response extensions.
+// Websocket topics are listed under x-orbital-topics
+func OpenAPI(title, version string, routes []RouteInfo, topics []TopicInfo) map[string]any {
+	ref := jsonschema.NewReflector("#/components/schemas/")
+
+	envelope := ref.Reflect(cryptographer.Message{})
+	errorReply := ref.Reflect(ErrorReply{})
+
+	envelopeContent := map[string]any{
+		"application/json": map[string]any{
+			"schema": envelope,
+		},
+	}
+
+	paths := make(map[string]any, len(routes))
+	for _, route := range routes {
+		method := strings.ToLower(route.Method)
+		if method == "" {
+			method = strings.ToLower(http.MethodPost)
+		}
+
+		ok := map[string]any{
+			"description": "Signed envelope",
+			"content":     envelopeContent,
+		}
+
+		if route.response != nil {
+			ok["x-orbital-response"] = ref.Reflect(route.response)
+		}
+
+		op := map[string]any{
+			"operationId": route.Service + "." + route.Action,
+			"tags":        []string{route.Service},
+			"requestBody": map[string]any{
+				"required": true,
+				"content":  envelopeContent,
+			},
+			"responses": map[string]any{
+				"200": ok,
+				"default": map[string]any{
+					"description":        "Signed envelope with an error reply",
+					"content":            envelopeContent,
+					"x-orbital-response": errorReply,
+				},
+			},
+		}
+
+		if route.Description != "" {
+			op["summary"] = route.Description
+		}
+
+		if route.Permission != "" {
+			op["x-orbital-permission"] = route.Permission
+		}
+
+		if route.request != nil {
+			op["x-orbital-request"] = ref.Reflect(route.request)
+		}
+
+		paths[route.Path] = map[string]any{
+			method: op,
+		}
+	}
+
+	wsTopics := make([]map[string]any, 0, len(topics))
+	for _, t := range topics {
+		topic := map[string]any{
+			"name": t.Name,
+		}
+
+		if t.Description != "" {
+			topic["description"] = t.Description
+		}
+
+		if t.Permission != "" {
+			topic["permission"] = t.Permission
+		}
+
+		if t.request != nil {
+			topic["request"] = ref.Reflect(t.request)
+		}
+
+		wsTopics = append(wsTopics, topic)
+	}
+
+	return map[string]any{
+		"openapi": openAPIVersion,
+		"info": map[string]any{
+			"title":   title,
+			"version": version,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": ref.Definitions(),
+		},
+		"x-orbital-topics": wsTopics,
+	}
+}
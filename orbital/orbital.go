@@ -1,13 +1,17 @@
 package orbital
 
 import (
+	"crypto/tls"
 	"embed"
 	"fmt"
 	"io/fs"
+	"net"
 	"net/http"
 	"orbital/config"
 	"orbital/pkg/cryptographer"
 	"orbital/pkg/logger"
+	"sync"
+	"sync/atomic"
 )
 
 //go:embed all:web/*
@@ -19,15 +23,33 @@ type Config struct {
 	Addr      string
 	Cfg       *config.Config
 	Logger    *logger.Logger
+	Signer    cryptographer.Signer // Optional. Defaults to a signer built from Cfg.SecretKey
+	Limiter   *RateLimiter         // Optional. Its budgets follow the reloads
+	Debug     bool                 // Keep the debug log level across reloads
 }
 
 type Orbital struct {
 	client    *http.Server
 	apiServer HTTPService
 	wsServer  WsService
+	signer    cryptographer.Signer
 	addr      string
 	cfg       *config.Config
 	log       *logger.Logger
+	checks    []HealthCheck
+	listening atomic.Bool
+	limiter   *RateLimiter
+	debug     bool
+	cors      atomic.Pointer[corsPolicy]
+	cert      atomic.Pointer[tls.Certificate] // Served certificate when TLS is on
+
+	reloadMu sync.Mutex
+	current  *config.Config // Config applied, the reloadable sections follow Reload
+}
+
+// Signer return the node signer. Inject it in services that need to sign messages
+func (n *Orbital) Signer() cryptographer.Signer {
+	return n.signer
 }
 
 func (n *Orbital) Start() error {
@@ -37,23 +59,85 @@ func (n *Orbital) Start() error {
 		return err
 	}
 
-	httpFS := http.FileServer(http.FS(staticFiles))
+	assetSrv, err := newAssetServer(staticFiles, n.log)
+	if err != nil {
+		return err
+	}
 
 	mux := http.NewServeMux()
-	mux.Handle("/", fsFileHandlerMiddleware(httpFS))
+	mux.Handle("/", assetSrv)
 	mux.Handle("/rpc/", n.apiServer)
 	mux.Handle("/ws", n.wsServer)
+	mux.HandleFunc("/sse", n.wsServer.ServeSSE)
+	mux.Handle("/metrics", MetricsHandler(n.cfg.Metrics))
+	mux.HandleFunc("/healthz", n.healthHandler(true))
+	mux.HandleFunc("/readyz", n.healthHandler(false))
 
-	handler := corsMiddleware(mux)
+	handler := corsMiddleware(&n.cors, mux)
 
 	n.client = &http.Server{
 		Addr:    n.addr,
 		Handler: handler,
 	}
 
-	n.log.Info("Starting Orbital", "addr", n.addr)
+	if n.cfg.TLS != nil {
+		cert, err := loadCertificate(*n.cfg.TLS)
+		if err != nil {
+			return err
+		}
+		n.cert.Store(cert)
+
+		n.client.TLSConfig = &tls.Config{
+			GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+				return n.cert.Load(), nil
+			},
+		}
+	}
+
+	n.log.Info("Starting Orbital", "addr", n.addr, "tls", n.cfg.TLS != nil)
+
+	addr := n.addr
+	if addr == "" {
+		addr = ":http"
+	}
 
-	if err = n.client.ListenAndServe(); err != nil {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("%w:[%v]", ErrHttpListen, err)
+	}
+
+	admin := config.DefaultAdmin()
+	if n.cfg.Admin != nil {
+		admin = *n.cfg.Admin
+	}
+	admin.Socket = n.cfg.AdminSocket()
+
+	if admin.Socket != "" {
+		adminSrv, adminLn, err := n.listenAdmin(admin)
+		if err != nil {
+			_ = ln.Close()
+			return err
+		}
+		defer adminSrv.close()
+
+		n.log.Info("Admin socket", "path", admin.Socket)
+		go func() {
+			if err := adminSrv.serve(adminLn); err != nil {
+				n.log.Error("admin socket stopped", "err", err)
+			}
+		}()
+	}
+
+	n.listening.Store(true)
+	defer n.listening.Store(false)
+
+	if n.cfg.TLS != nil {
+		// The certificate comes from TLSConfig so a reload can swap it
+		err = n.client.ServeTLS(ln, "", "")
+	} else {
+		err = n.client.Serve(ln)
+	}
+	if err != nil {
 		return fmt.Errorf("%w:[%v]", ErrHttpListen, err)
 	}
 
@@ -71,22 +155,41 @@ func New(cfg Config) (*Orbital, error) {
 		lg = logger.New(logger.LevelDebug, logger.FormatString)
 	}
 
-	sk, err := cryptographer.NewPrivateKeyFromHex(cfg.Cfg.SecretKey)
-	if err != nil {
-		return nil, err
+	signer := cfg.Signer
+	if signer == nil {
+		sk, err := cryptographer.NewPrivateKeyFromHex(cfg.Cfg.SecretKey)
+		if err != nil {
+			return nil, err
+		}
+		signer = sk
 	}
 
 	apiSrv := cfg.ApiServer
-	apiSrv.SetSecretKey(sk)
+	apiSrv.SetSigner(signer)
 
 	wsSrv := cfg.WsServer
-	wsSrv.SetSecretKey(sk)
+	wsSrv.SetSigner(signer)
+	wsSrv.SetRPCHandler(apiSrv)
+
+	if cors := cfg.Cfg.CORS; cors != nil {
+		if err := cors.Validate(); err != nil {
+			return nil, err
+		}
+		wsSrv.SetOriginPatterns(cors.AllowedOrigins)
+	}
 
-	return &Orbital{
+	n := &Orbital{
 		apiServer: apiSrv,
 		wsServer:  wsSrv,
+		signer:    signer,
 		addr:      cfg.Addr,
 		cfg:       cfg.Cfg,
 		log:       lg,
-	}, nil
+		limiter:   cfg.Limiter,
+		debug:     cfg.Debug,
+		current:   cfg.Cfg,
+	}
+	n.cors.Store(newCORSPolicy(cfg.Cfg.CORS))
+
+	return n, nil
 }
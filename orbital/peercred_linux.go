@@ -0,0 +1,65 @@
+package orbital
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// peerCredentials read the credentials of the process at the other end of a Unix socket.
+// Both are the ones the kernel saved at connect, the pid may already be reused by another process
+func peerCredentials(c net.Conn) (AdminPeer, error) {
+	uc, ok := c.(*net.UnixConn)
+	if !ok {
+		return AdminPeer{}, fmt.Errorf("%w:[not a unix connection]", ErrPeerCred)
+	}
+
+	raw, err := uc.SyscallConn()
+	if err != nil {
+		return AdminPeer{}, fmt.Errorf("%w:[%v]", ErrPeerCred, err)
+	}
+
+	var (
+		cred    *unix.Ucred
+		groups  []uint32
+		credErr error
+	)
+	err = raw.Control(func(fd uintptr) {
+		if cred, credErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED); credErr == nil {
+			groups, credErr = peerGroups(int(fd))
+		}
+	})
+	if err == nil {
+		err = credErr
+	}
+	if err != nil {
+		return AdminPeer{}, fmt.Errorf("%w:[%v]", ErrPeerCred, err)
+	}
+
+	return AdminPeer{UID: cred.Uid, GID: cred.Gid, PID: cred.Pid, Groups: groups}, nil
+}
+
+// peerGroups supplementary groups of the peer with SO_PEERGROUPS. Kernels before 4.13 lack it, the peer then has none
+func peerGroups(fd int) ([]uint32, error) {
+	groups := make([]uint32, 32)
+	for {
+		size := uint32(len(groups) * 4)
+		_, _, errno := unix.Syscall6(unix.SYS_GETSOCKOPT, uintptr(fd), unix.SOL_SOCKET, unix.SO_PEERGROUPS,
+			uintptr(unsafe.Pointer(&groups[0])), uintptr(unsafe.Pointer(&size)), 0)
+
+		switch {
+		case errno == 0:
+			return groups[:size/4], nil
+		case errors.Is(errno, unix.ENOPROTOOPT):
+			return nil, nil
+		case errors.Is(errno, unix.ERANGE) && int(size/4) > len(groups):
+			// The size needed is returned
+			groups = make([]uint32, size/4)
+		default:
+			return nil, errno
+		}
+	}
+}
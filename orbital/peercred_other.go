@@ -0,0 +1,14 @@
+//go:build !linux
+
+package orbital
+
+import (
+	"fmt"
+	"net"
+	"runtime"
+)
+
+// peerCredentials SO_PEERCRED is Linux only. Every peer is refused elsewhere
+func peerCredentials(net.Conn) (AdminPeer, error) {
+	return AdminPeer{}, fmt.Errorf("%w:[unsupported on %s]", ErrPeerCred, runtime.GOOS)
+}
@@ -0,0 +1,239 @@
+package orbital
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"orbital/config"
+	"orbital/pkg/ratelimit"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// RateLimiter token buckets shared by the HTTP and websocket servers
+	RateLimiter struct {
+		limits atomic.Pointer[limits] // Swapped by Update
+
+		mu      sync.Mutex
+		wsConns map[string]int // Open websocket and stream connections per IP
+	}
+
+	// limits budgets of a RateLimiter
+	limits struct {
+		ip           *ratelimit.Limiter
+		key          *ratelimit.Limiter
+		routes       map[string]*ratelimit.Limiter
+		wsMessages   *ratelimit.Limiter
+		wsConnsPerIP int
+	}
+
+	// LimiterState settings and buckets of one limiter
+	LimiterState struct {
+		Name    string            `json:"name"`
+		Rate    ratelimit.Rate    `json:"rate"`
+		Buckets []ratelimit.State `json:"buckets"`
+	}
+
+	RateLimiterState struct {
+		Limiters     []LimiterState `json:"limiters"`
+		WsConns      map[string]int `json:"wsConns"`
+		WsConnsPerIP int            `json:"wsConnsPerIP"`
+	}
+)
+
+func NewRateLimiter(cfg config.RateLimit) *RateLimiter {
+	rl := &RateLimiter{
+		wsConns: make(map[string]int),
+	}
+	rl.Update(cfg)
+
+	return rl
+}
+
+// Update apply new budgets. The buckets of the limiters whose rate is unchanged are kept
+func (rl *RateLimiter) Update(cfg config.RateLimit) {
+	current := rl.limits.Load()
+	if current == nil {
+		current = &limits{}
+	}
+
+	next := &limits{
+		ip:           keepLimiter(current.ip, cfg.IP),
+		key:          keepLimiter(current.key, cfg.Key),
+		routes:       make(map[string]*ratelimit.Limiter, len(cfg.Routes)),
+		wsMessages:   keepLimiter(current.wsMessages, cfg.WsMessages),
+		wsConnsPerIP: cfg.WsConnsPerIP,
+	}
+
+	for route, rate := range cfg.Routes {
+		next.routes[route] = keepLimiter(current.routes[route], rate)
+	}
+
+	rl.limits.Store(next)
+}
+
+// keepLimiter return the current limiter when its rate is unchanged, a new one otherwise
+func keepLimiter(current *ratelimit.Limiter, rate ratelimit.Rate) *ratelimit.Limiter {
+	next := ratelimit.New(rate)
+	if current != nil && current.Rate() == next.Rate() {
+		return current
+	}
+	return next
+}
+
+// Middleware limit the requests per remote IP, and per route for the routes with their own budget.
+// It runs before the signature verification so floods stay cheap
+func (rl *RateLimiter) Middleware(server HTTPService) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			// Admin socket peers are local and trusted
+			if _, local := LocalAdmin(r.Context()); local {
+				next(w, r)
+				return
+			}
+
+			ip, l := remoteIP(r.RemoteAddr), rl.limits.Load()
+
+			if err := rl.allow(l.ip, ip, "ip"); err != nil {
+				server.OnError(w, r, err)
+				return
+			}
+
+			if limiter, found := l.routes[r.URL.Path]; found {
+				if err := rl.allow(limiter, ip, "route"); err != nil {
+					server.OnError(w, r, err)
+					return
+				}
+			}
+
+			next(w, r)
+		}
+	}
+}
+
+// AllowKey take a token from the public key budget
+func (rl *RateLimiter) AllowKey(publicKey string) error {
+	if rl == nil || publicKey == "" {
+		return nil
+	}
+
+	return rl.allow(rl.limits.Load().key, publicKey, "key")
+}
+
+// AllowWsMessage take a token from the connection message budget
+func (rl *RateLimiter) AllowWsMessage(connID string) error {
+	if rl == nil {
+		return nil
+	}
+
+	return rl.allow(rl.limits.Load().wsMessages, connID, "wsMessages")
+}
+
+// OpenConn check the IP budget and connection count before a websocket or stream opens.
+// The returned release must be called when the connection closes
+func (rl *RateLimiter) OpenConn(remoteAddr string) (release func(), err error) {
+	if rl == nil {
+		return func() {}, nil
+	}
+
+	ip, l := remoteIP(remoteAddr), rl.limits.Load()
+	if err = rl.allow(l.ip, ip, "ip"); err != nil {
+		return nil, err
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if l.wsConnsPerIP > 0 && rl.wsConns[ip] >= l.wsConnsPerIP {
+		return nil, NewError(ResourceExhausted, "rateLimit.tooManyConnections", "too many connections").
+			WithDetails(map[string]any{"max": l.wsConnsPerIP})
+	}
+	rl.wsConns[ip]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			rl.mu.Lock()
+			defer rl.mu.Unlock()
+
+			if rl.wsConns[ip]--; rl.wsConns[ip] <= 0 {
+				delete(rl.wsConns, ip)
+			}
+		})
+	}, nil
+}
+
+// State return the limiters settings and buckets
+func (rl *RateLimiter) State() RateLimiterState {
+	if rl == nil {
+		return RateLimiterState{}
+	}
+
+	l := rl.limits.Load()
+	limiters := []LimiterState{
+		limiterState("ip", l.ip),
+		limiterState("key", l.key),
+		limiterState("wsMessages", l.wsMessages),
+	}
+
+	routes := make([]string, 0, len(l.routes))
+	for route := range l.routes {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+
+	for _, route := range routes {
+		limiters = append(limiters, limiterState("route:"+route, l.routes[route]))
+	}
+
+	rl.mu.Lock()
+	conns := make(map[string]int, len(rl.wsConns))
+	for ip, n := range rl.wsConns {
+		conns[ip] = n
+	}
+	rl.mu.Unlock()
+
+	return RateLimiterState{
+		Limiters:     limiters,
+		WsConns:      conns,
+		WsConnsPerIP: l.wsConnsPerIP,
+	}
+}
+
+func (rl *RateLimiter) allow(limiter *ratelimit.Limiter, key, scope string) error {
+	ok, wait := limiter.Allow(key)
+	if ok {
+		return nil
+	}
+
+	return NewError(ResourceExhausted, "rateLimit.exceeded", "rate limit exceeded").
+		WithDetails(map[string]any{"scope": scope}).
+		WithRetryAfter(wait)
+}
+
+func limiterState(name string, limiter *ratelimit.Limiter) LimiterState {
+	return LimiterState{
+		Name:    name,
+		Rate:    limiter.Rate(),
+		Buckets: limiter.State(),
+	}
+}
+
+// remoteIP strip the port of a remote address
+func remoteIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+
+	return host
+}
+
+// retryAfterSeconds value of the Retry-After header, rounded up
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
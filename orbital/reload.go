@@ -0,0 +1,151 @@
+package orbital
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"orbital/config"
+	"orbital/pkg/logger"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// reloadPollInterval how often WatchReload checks the config file
+const reloadPollInterval = 2 * time.Second
+
+// fileStamp modification time and size telling a file changed
+type fileStamp struct {
+	mod  time.Time
+	size int64
+}
+
+// Reload read the config file again and apply its reloadable sections: log, rateLimit, cors and the TLS
+// certificate. The other changes are only reported, they need a restart. An invalid config applies nothing
+func (n *Orbital) Reload() ([]config.Change, error) {
+	n.reloadMu.Lock()
+	defer n.reloadMu.Unlock()
+
+	next, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("%w:[%v]", ErrReloadInvalid, err)
+	}
+	if err = next.Validate(); err != nil {
+		return nil, fmt.Errorf("%w:[%v]", ErrReloadInvalid, err)
+	}
+
+	changes, err := config.Diff(n.current, next)
+	if err != nil {
+		return nil, fmt.Errorf("%w:[%v]", ErrReloadInvalid, err)
+	}
+
+	// What can fail runs before anything is applied. The certificate is read even when the paths are
+	// unchanged, a renewed one is usually written over the old files
+	var cert *tls.Certificate
+	if n.current.TLS != nil && next.TLS != nil {
+		if cert, err = loadCertificate(*next.TLS); err != nil {
+			return nil, fmt.Errorf("%w:[%v]", ErrReloadInvalid, err)
+		}
+	}
+
+	applied := *n.current
+	applied.Log, applied.RateLimit, applied.CORS = next.Log, next.RateLimit, next.CORS
+
+	logCfg := config.DefaultLog()
+	if next.Log != nil {
+		logCfg = *next.Log
+	}
+	logLvl := logCfg.LoggerLevel()
+	if n.debug {
+		logLvl = logger.LevelDebug
+	}
+	n.log.Configure(logLvl, logCfg.LoggerFormat())
+
+	if n.limiter != nil {
+		rateLimit := config.DefaultRateLimit()
+		if next.RateLimit != nil {
+			rateLimit = *next.RateLimit
+		}
+		n.limiter.Update(rateLimit)
+	}
+
+	var origins []string
+	if next.CORS != nil {
+		origins = next.CORS.AllowedOrigins
+	}
+	n.cors.Store(newCORSPolicy(next.CORS))
+	n.wsServer.SetOriginPatterns(origins)
+
+	if cert != nil {
+		n.cert.Store(cert)
+		applied.TLS = next.TLS
+	}
+
+	n.current = &applied
+
+	for _, change := range changes {
+		if change.Reloadable {
+			n.log.Info("Config applied", "key", change.Key, "old", change.Old, "new", change.New)
+		} else {
+			n.log.Warn("Config change needs a restart", "key", change.Key, "old", change.Old, "new", change.New)
+		}
+	}
+
+	return changes, nil
+}
+
+// WatchReload reload the config on SIGHUP and when the config file changes, until ctx is done.
+// A failed reload is logged and the node keeps its current config
+func (n *Orbital) WatchReload(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(reloadPollInterval)
+	defer ticker.Stop()
+
+	last := statFile(config.Path())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			n.reload("signal")
+		case <-ticker.C:
+			stamp := statFile(config.Path())
+			if stamp == last {
+				continue
+			}
+			last = stamp
+			n.reload("file")
+		}
+	}
+}
+
+func (n *Orbital) reload(trigger string) {
+	changes, err := n.Reload()
+	if err != nil {
+		n.log.Error("config reload failed", "trigger", trigger, "err", err)
+		return
+	}
+
+	n.log.Info("Config reloaded", "trigger", trigger, "changes", len(changes))
+}
+
+// statFile stamp of the file, zero when it cannot be read
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{mod: info.ModTime(), size: info.Size()}
+}
+
+func loadCertificate(cfg config.TLS) (*tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w:[%v]", ErrTLSCertificate, err)
+	}
+	return &cert, nil
+}
@@ -0,0 +1,86 @@
+package orbital
+
+import (
+	"context"
+	"orbital/pkg/cryptographer"
+	"sync"
+)
+
+// requestState what the middlewares learn while serving a request.
+// Shared through the context so the outer middlewares read it once the handler returned
+type requestState struct {
+	mu        sync.Mutex
+	code      Code
+	codeSet   bool
+	publicKey string
+	body      []byte
+}
+
+// withRequestState attach a new state to the context
+func withRequestState(ctx context.Context) (context.Context, *requestState) {
+	state := &requestState{}
+	return context.WithValue(ctx, requestStateCtxKey, state), state
+}
+
+// requestStateOf return the state of the request. Never nil
+func requestStateOf(ctx context.Context) *requestState {
+	if state, ok := ctx.Value(requestStateCtxKey).(*requestState); ok {
+		return state
+	}
+
+	return &requestState{}
+}
+
+// replyCode return the code of the error replied, OK if none was
+func (s *requestState) replyCode() (Code, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.code, s.codeSet
+}
+
+// caller return the key and body set by WithCaller, else the ones carried by ctx
+func (s *requestState) caller(ctx context.Context) (string, []byte) {
+	s.mu.Lock()
+	publicKey, body := s.publicKey, s.body
+	s.mu.Unlock()
+
+	if publicKey == "" {
+		publicKey, _ = ctx.Value(cryptographer.PublicKeyCtxKey).(string)
+	}
+	if body == nil {
+		body, _ = ctx.Value(cryptographer.BodyCtxKey).([]byte)
+	}
+
+	return publicKey, body
+}
+
+// setReplyCode record the code of the error replied to the request
+func setReplyCode(ctx context.Context, code Code) {
+	state := requestStateOf(ctx)
+
+	state.mu.Lock()
+	state.code = code
+	state.codeSet = true
+	state.mu.Unlock()
+}
+
+// WithCaller carry the verified signer key and the envelope body of the request.
+// The handlers read them from the context, the audit from the request state
+func WithCaller(ctx context.Context, publicKey string, body []byte) context.Context {
+	state := requestStateOf(ctx)
+
+	state.mu.Lock()
+	state.publicKey = publicKey
+	state.body = body
+	state.mu.Unlock()
+
+	ctx = context.WithValue(ctx, cryptographer.BodyCtxKey, body)
+	return context.WithValue(ctx, cryptographer.PublicKeyCtxKey, publicKey)
+}
+
+// SignerKey return the verified key that signed the request envelope. Empty for admin socket peers
+func SignerKey(ctx context.Context) string {
+	publicKey, _ := ctx.Value(cryptographer.PublicKeyCtxKey).(string)
+	return publicKey
+}
@@ -0,0 +1,85 @@
+package orbital
+
+import (
+	"context"
+	"net/http"
+	"orbital/pkg/logger"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carry the request id of HTTP calls, both ways
+const RequestIDHeader = "X-Request-Id"
+
+var tracer = otel.Tracer("orbital")
+
+// WithRequestID carry the request id in the context. It is returned by CorrelationID,
+// set on the signed replies and appended to the logs made with the context
+func WithRequestID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, correlationIDCtxKey, id)
+	return logger.WithAttrs(ctx, "requestId", id)
+}
+
+func newRequestID() string {
+	return uuid.NewString()
+}
+
+// startSpan start a span, child of the one in ctx, and add its trace id to the logs
+func startSpan(ctx context.Context, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	parent := trace.SpanContextFromContext(ctx)
+
+	ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+
+	// Only on the first local span of the trace, children already carry it
+	if sc := span.SpanContext(); sc.IsValid() && (!parent.IsValid() || parent.IsRemote()) {
+		ctx = logger.WithAttrs(ctx, "traceId", sc.TraceID().String())
+	}
+
+	return ctx, span
+}
+
+// endSpan mark the span failed for codes other than OK and end it
+func endSpan(span trace.Span, code Code) {
+	span.SetAttributes(attribute.String("orbital.code", code.String()))
+	if code != OK {
+		span.SetStatus(codes.Error, code.String())
+	}
+	span.End()
+}
+
+// TraceMiddleware give every request an id and a span.
+// The id is the one of the websocket call, the X-Request-Id header or a new one, and is returned in the X-Request-Id header.
+// A W3C traceparent header continues the caller trace
+func TraceMiddleware() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			id := CorrelationID(ctx)
+			if id == "" {
+				id = r.Header.Get(RequestIDHeader)
+				if id == "" || len(id) > 128 {
+					id = newRequestID()
+				}
+				ctx = WithRequestID(ctx, id)
+			}
+			w.Header().Set(RequestIDHeader, id)
+
+			ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
+			ctx, span := startSpan(ctx, r.URL.Path, trace.SpanKindServer,
+				attribute.String("http.method", r.Method),
+				attribute.String("orbital.requestId", id),
+			)
+
+			next(w, r.WithContext(ctx))
+
+			code, _ := requestStateOf(ctx).replyCode()
+			endSpan(span, code)
+		}
+	}
+}
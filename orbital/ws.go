@@ -2,45 +2,96 @@ package orbital
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"orbital/pkg/cryptographer"
 	"orbital/pkg/logger"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/coder/websocket"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type (
 	HandlerFunc func(ctx context.Context, connID string, data []byte)
 
+	// Topic a websocket topic. Without Handler the topic is publish only and clients subscribe to it
 	Topic struct {
 		Name    string
 		Handler HandlerFunc
+
+		Permission string // Permission required to send to the topic, or to subscribe to a publish only topic
+
+		// Introspection only
+		Description string
+		Request     any // Zero value of the message body type
 	}
 
 	WsService interface {
-		SetSecretKey(secretKey cryptographer.PrivateKey)
+		SetSigner(signer cryptographer.Signer)
 		Register(topic Topic)
 		Broadcast(ctx context.Context, m cryptographer.Message)
+		Publish(ctx context.Context, topic string, m cryptographer.Message)
+		HasSubscribers(topic string) bool
+		SetAuthorizer(authorize AuthorizeFunc)
+		SetAuthenticator(authenticate AuthenticateFunc)
+		SendToUser(ctx context.Context, userID string, m cryptographer.Message) error
+		BroadcastToRole(ctx context.Context, role string, m cryptographer.Message)
+		DisconnectKey(publicKey string)
+		Kick(connID, reason string) error
+		OnPresence(fn PresenceFunc)
+		Connections() []WsConnectionStats
+		OnlineUsers() []string
+		SetReplayStore(store ReplayStore)
+		SetRateLimiter(limiter *RateLimiter)
+		SetAuditor(audit AuditFunc)
+		SetOriginPatterns(patterns []string)
 		SendTo(ctx context.Context, connectionID string, m cryptographer.Message) error
+		ReplyError(ctx context.Context, connectionID string, meta cryptographer.Metadata, err error) error
+		Topics() []TopicInfo
+		SetRPCHandler(h http.Handler)
 		ServeHTTP(w http.ResponseWriter, r *http.Request)
+		ServeSSE(w http.ResponseWriter, r *http.Request)
 	}
 
 	WsConn struct {
-		secretKey         cryptographer.PrivateKey
-		log               *logger.Logger
-		topics            map[string]Topic
-		connectionManager *WsConnectionManager
-		writeTimeout      time.Duration
-		idleTimeout       time.Duration
+		signer             cryptographer.Signer
+		log                *logger.Logger
+		topics             map[string]Topic
+		connectionManager  *WsConnectionManager
+		idleTimeout        time.Duration
+		rpcHandler         http.Handler
+		callTimeout        time.Duration // Upper bound for calls over the websocket
+		maxInflight        int           // Concurrent calls allowed per connection
+		subscriptions      *WsSubscriptions
+		authorize          AuthorizeFunc
+		authenticate       AuthenticateFunc
+		revalidateInterval time.Duration // How often the key of an authenticated connection is checked
+		presence           []PresenceFunc
+		publishMu          sync.Mutex // Orders publishes and replays
+		replay             ReplayStore
+		originPatterns     atomic.Pointer[[]string] // Browser origins allowed besides the node one
+		seqs               map[string]uint64
+		limiter            *RateLimiter
+		audit              AuditFunc
 	}
 )
 
-func (ws *WsConn) SetSecretKey(secretKey cryptographer.PrivateKey) {
-	ws.secretKey = secretKey
+func (ws *WsConn) SetSigner(signer cryptographer.Signer) {
+	ws.signer = signer
+}
+
+// SetRateLimiter limit the connections per IP and the messages per connection
+func (ws *WsConn) SetRateLimiter(limiter *RateLimiter) {
+	ws.limiter = limiter
 }
 
 func (ws *WsConn) Register(topic Topic) {
@@ -63,64 +114,89 @@ func (ws *WsConn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		err    error
 	)
 
+	release, err := ws.limiter.OpenConn(r.RemoteAddr)
+	if err != nil {
+		ws.replyHTTPError(w, err)
+		return
+	}
+	defer release()
+
 	wsConn, err = websocket.Accept(w, r, &websocket.AcceptOptions{
-		InsecureSkipVerify: true,
+		OriginPatterns: ws.originPatternsList(),
 	})
 	if err != nil {
-		_ = Encode(w, r, http.StatusInternalServerError, Error{
-			Internal,
-			err,
-		})
+		// Accept already replied to the client
+		ws.log.ErrorContext(ctx, "websocket accept failed", "err", err)
 		return
 	}
 
-	ws.handleConnection(ctx, wsConn)
+	ws.handleConnection(ctx, wsConn, r.RemoteAddr)
 }
 
+// Broadcast queue the message on every connection
 func (ws *WsConn) Broadcast(ctx context.Context, m cryptographer.Message) {
-
-	if _, ok := ctx.Deadline(); !ok {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, ws.writeTimeout)
-		defer cancel()
-	}
-
 	raw, err := json.Marshal(m)
 	if err != nil {
-		fmt.Println("cannot marshal message for broadcast")
+		ws.log.ErrorContext(ctx, "cannot marshal message for broadcast", "err", err)
 		return
 	}
 
 	ws.connectionManager.Broadcast(ctx, raw)
 }
 
+// SendTo queue the message on the connection. ErrSendQueueFull is returned when the client is too slow
 func (ws *WsConn) SendTo(ctx context.Context, connID string, m cryptographer.Message) error {
-
-	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
-	defer cancel()
-
 	raw, err := json.Marshal(m)
 	if err != nil {
 		return err
 	}
 
 	return ws.connectionManager.SendTo(ctx, connID, raw)
+}
 
+// SetTimeouts set how long a connection may stay silent and the budget of an RPC call. Zero keeps the current value
+func (ws *WsConn) SetTimeouts(idle, call time.Duration) {
+	if idle > 0 {
+		ws.idleTimeout = idle
+	}
+	if call > 0 {
+		ws.callTimeout = call
+	}
 }
 
-func (ws *WsConn) handleConnection(ctx context.Context, conn *websocket.Conn) {
-	connID := genConnID()
-	ws.connectionManager.AddConnection(connID, conn)
+// SetQueueConfig set the outbound queue size and overflow policy of new connections
+func (ws *WsConn) SetQueueConfig(cfg WsQueueConfig) {
+	ws.connectionManager.SetQueueConfig(cfg)
+}
 
-	defer func() {
-		ws.connectionManager.RemoveConnection(connID)
-		_ = conn.Close(websocket.StatusNormalClosure, "closing connection")
-	}()
+// Connections return the counters of the open connections
+func (ws *WsConn) Connections() []WsConnectionStats {
+	return ws.connectionManager.Stats()
+}
+
+// ReplyError send a signed ErrorReply to a connection.
+// The reply keeps the request domain, action and correlation id so the client routes it to the same topic
+func (ws *WsConn) ReplyError(ctx context.Context, connID string, meta cryptographer.Metadata, err error) error {
+	e := AsError(err)
+	setReplyCode(ctx, e.Code)
 
-	ws.log.Info("New connection joined", "connID", connID)
+	ws.log.ErrorContext(ctx, "ws request failed", "connId", connID, "code", e.Code.String(), "err", e.logCause(err))
 
-	connCtx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	msg, err := cryptographer.Encode(ws.signer, cryptographer.Metadata{
+		Domain:        meta.Domain,
+		Action:        meta.Action,
+		CorrelationID: meta.CorrelationID,
+	}, e.Reply())
+	if err != nil {
+		return err
+	}
+
+	return ws.SendTo(ctx, connID, *msg)
+}
+
+func (ws *WsConn) handleConnection(ctx context.Context, conn *websocket.Conn, remoteAddr string) {
+	wsConnection, connCtx := ws.openConnection(ctx, conn, remoteAddr)
+	defer ws.closeConnection(wsConnection)
 
 	// Adjust the pingInterval in case idleTimeout is set
 	pingInterval := 30 * time.Second
@@ -129,10 +205,7 @@ func (ws *WsConn) handleConnection(ctx context.Context, conn *websocket.Conn) {
 	}
 
 	// Start heartbeat
-	go keepAlive(connCtx, conn, pingInterval, 5*time.Second)
-
-	// Welcome the client
-	ws.sendWelcomeMessage(connCtx, connID)
+	go keepAlive(connCtx, conn, wsConnection, pingInterval, 5*time.Second)
 
 	for {
 		readCtx := connCtx
@@ -146,39 +219,146 @@ func (ws *WsConn) handleConnection(ctx context.Context, conn *websocket.Conn) {
 		}
 
 		if err != nil {
-			ws.log.Error(err.Error(), "connection", "read error", "resolution", "closing connection")
+			ws.log.InfoContext(connCtx, "closing connection on read error", "err", err)
 			return
 		}
 
-		var message cryptographer.Message
-		if err = json.Unmarshal(msg, &message); err != nil {
-			ws.log.Error(err.Error(), "connection", "unmarshal error", "resolution", "skip message")
-			continue
-		}
+		ws.handleMessage(wsConnection, msg)
+	}
+}
 
-		// TODO: Add verify message
+// openConnection register the connection, welcome the client and watch its key.
+// The returned context ends with the connection
+func (ws *WsConn) openConnection(ctx context.Context, transport WsTransport, remoteAddr string) (*WsConnection, context.Context) {
+	connID := genConnID()
+	conn := ws.connectionManager.AddConnection(connID, transport, remoteAddr)
 
-		t, err := topic(message.Metadata.Domain, message.Metadata.Action)
-		if err != nil {
-			ws.log.Error(err.Error(), "topic parsing error")
-			continue
-		}
+	conn.ctx, conn.cancel = context.WithCancel(logger.WithAttrs(ctx, "connId", connID))
 
-		handler, found := ws.topics[t]
-		if !found {
-			ws.log.Error("topic field is missing", "topic", t)
-			continue
-		}
+	ws.log.InfoContext(conn.ctx, "New connection joined", "remoteAddr", remoteAddr)
+	conn.calls = newWsCalls(ws.maxInflight)
+
+	// Welcome the client
+	ws.sendWelcomeMessage(conn.ctx, conn)
+
+	// Close the connection once its key is revoked
+	go ws.revalidate(conn.ctx, conn, ws.revalidateInterval)
+
+	return conn, conn.ctx
+}
+
+// closeConnection forget the connection and tell the presence hooks the user left
+func (ws *WsConn) closeConnection(conn *WsConnection) {
+	identity := ws.connectionManager.Identity(conn.ID)
+
+	ws.connectionManager.RemoveConnection(conn.ID)
+	ws.subscriptions.RemoveAll(conn.ID)
+	conn.cancel()
 
-		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					ws.log.Error("recovered from panic", "topic", t, "err", r)
-				}
-			}()
-			handler.Handler(connCtx, connID, msg)
-		}()
+	if identity != nil {
+		ws.notifyPresence(context.Background(), conn.ID, *identity, false)
 	}
+	_ = conn.Conn.Close(websocket.StatusNormalClosure, "closing connection")
+}
+
+// handleMessage route a message received from the client, whatever its transport
+func (ws *WsConn) handleMessage(conn *WsConnection, msg []byte) {
+	connCtx, connID := conn.ctx, conn.ID
+
+	conn.RecordIn(len(msg))
+
+	if err := ws.limiter.AllowWsMessage(connID); err != nil {
+		_ = ws.ReplyError(connCtx, connID, cryptographer.Metadata{
+			Domain: "system",
+			Action: "error",
+		}, err)
+		return
+	}
+
+	var message cryptographer.Message
+	if err := json.Unmarshal(msg, &message); err != nil {
+		ws.log.ErrorContext(connCtx, "cannot decode message, skipped", "err", err)
+		return
+	}
+
+	// Envelopes are verified where it matters: the handshake, subscriptions, RPC calls and permissioned
+	// topics. Keepalives and other open topics may be sent unsigned
+
+	// Every message is logged and traced with its correlation id, or a new request id
+	requestID := message.Metadata.CorrelationID
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+	msgCtx, _ := withRequestState(WithRequestID(connCtx, requestID))
+	msgCtx = otel.GetTextMapPropagator().Extract(msgCtx, propagation.MapCarrier(message.Metadata.Tags))
+
+	if message.Metadata.Domain == rpcDomain {
+		ws.handleCall(msgCtx, connID, conn.RemoteAddr, conn.calls, message, msg)
+		return
+	}
+
+	t, err := topic(message.Metadata.Domain, message.Metadata.Action)
+	if err != nil {
+		_ = ws.ReplyError(msgCtx, connID, cryptographer.Metadata{
+			Domain: "system",
+			Action: "error",
+		}, NewError(InvalidRequest, "ws.invalidTopic", err.Error()))
+		return
+	}
+
+	if t == cancelTopic {
+		conn.calls.cancel(message.Metadata.CorrelationID)
+		return
+	}
+
+	msgCtx, span := startSpan(msgCtx, t, trace.SpanKindServer,
+		attribute.String("orbital.connId", connID),
+		attribute.String("orbital.requestId", requestID),
+	)
+	defer func() {
+		code, _ := requestStateOf(msgCtx).replyCode()
+		endSpan(span, code)
+	}()
+
+	start := time.Now()
+	meta := message.Metadata
+	envelopeKey := hex.EncodeToString(message.PublicKey[:])
+
+	switch t {
+	case authenticateTopic:
+		defer ws.auditCommand(msgCtx, conn, meta.Domain, meta.Action, envelopeKey, message.Body, start)
+		ws.handleAuthenticate(msgCtx, conn, message)
+		return
+	case subscribeTopic, unsubscribeTopic:
+		defer ws.auditCommand(msgCtx, conn, meta.Domain, meta.Action, envelopeKey, message.Body, start)
+		ws.handleSubscription(msgCtx, connID, t, message)
+		return
+	}
+
+	handler, found := ws.topics[t]
+	if !found || handler.Handler == nil {
+		_ = ws.ReplyError(msgCtx, connID, message.Metadata, NewError(NotFound, "ws.topicNotFound", "topic not found").
+			WithDetails(map[string]any{"topic": t}))
+		return
+	}
+
+	// Only privileged topics are audited, not the keepalive and such
+	if handler.Permission != "" {
+		defer ws.auditCommand(msgCtx, conn, meta.Domain, meta.Action, envelopeKey, message.Body, start)
+
+		if err = ws.authorizeMessage(msgCtx, connID, message, handler.Permission); err != nil {
+			_ = ws.ReplyError(msgCtx, connID, meta, err)
+			return
+		}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			setReplyCode(msgCtx, Internal)
+			ws.log.ErrorContext(msgCtx, "recovered from panic", "topic", t, "err", r)
+		}
+	}()
+	handler.Handler(msgCtx, connID, msg)
 }
 
 type WelcomeMessage struct {
@@ -188,33 +368,38 @@ type WelcomeMessage struct {
 	Error      *ErrorResponse `json:"error,omitempty"`
 }
 
-func (ws *WsConn) sendWelcomeMessage(ctx context.Context, connID string) {
-	msg, err := cryptographer.Encode(ws.secretKey, cryptographer.Metadata{
+func (ws *WsConn) sendWelcomeMessage(ctx context.Context, conn *WsConnection) {
+	msg, err := cryptographer.Encode(ws.signer, cryptographer.Metadata{
 		Domain: "system",
 		Action: "welcome",
 	}, WelcomeMessage{
 		Code:       OK,
-		ConnID:     connID,
-		ServerTime: time.Now().Unix(),
+		ConnID:     conn.ID,
+		ServerTime: conn.WelcomeTime,
 	})
 	if err != nil {
-		ws.log.Error(err.Error(), "welcome message encoding error")
+		ws.log.ErrorContext(ctx, "cannot encode welcome message", "err", err)
 		return
 	}
 
-	if err = ws.SendTo(ctx, connID, *msg); err != nil {
-		ws.log.Error(err.Error(), "welcome message sending error")
+	if err = ws.SendTo(ctx, conn.ID, *msg); err != nil {
+		ws.log.ErrorContext(ctx, "cannot send welcome message", "err", err)
 		return
 	}
 }
 
 func NewWsConn(log *logger.Logger) *WsConn {
 	wsConn := &WsConn{
-		log:               log,
-		topics:            make(map[string]Topic),
-		connectionManager: NewWsConnectionManager(),
-		writeTimeout:      5 * time.Second,
-		idleTimeout:       30 * time.Second,
+		log:                log,
+		topics:             make(map[string]Topic),
+		connectionManager:  NewWsConnectionManager(),
+		idleTimeout:        30 * time.Second,
+		callTimeout:        30 * time.Second,
+		maxInflight:        32,
+		subscriptions:      NewWsSubscriptions(),
+		revalidateInterval: time.Minute,
+		replay:             NewMemoryReplayStore(256),
+		seqs:               make(map[string]uint64),
 	}
 
 	return wsConn
@@ -237,7 +422,7 @@ func topic(domain, action string, cid ...string) (string, error) {
 	return t, nil
 }
 
-func keepAlive(ctx context.Context, conn *websocket.Conn, interval, timeout time.Duration) {
+func keepAlive(ctx context.Context, socket *websocket.Conn, conn *WsConnection, interval, timeout time.Duration) {
 	tick := time.NewTicker(interval)
 	defer tick.Stop()
 
@@ -247,13 +432,15 @@ func keepAlive(ctx context.Context, conn *websocket.Conn, interval, timeout time
 			return
 		case <-tick.C:
 			kaCtx, cancel := context.WithTimeout(ctx, timeout)
-			if err := conn.Ping(kaCtx); err != nil {
+			if err := socket.Ping(kaCtx); err != nil {
 				cancel()
 
-				_ = conn.Close(websocket.StatusPolicyViolation, "keep alive error")
+				_ = socket.Close(websocket.StatusPolicyViolation, "keep alive error")
 				return
 			}
 			cancel()
+
+			conn.RecordPong()
 		}
 	}
 }
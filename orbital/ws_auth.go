@@ -0,0 +1,174 @@
+package orbital
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"orbital/pkg/cryptographer"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+const authenticateTopic = "system/authenticate"
+
+type (
+	// WsIdentity user bound to a connection after the system/authenticate handshake
+	WsIdentity struct {
+		UserID string
+		Role   string
+	}
+
+	// AuthenticateFunc resolve the user owning the public key.
+	// An Unauthenticated error means the key is unknown or revoked
+	AuthenticateFunc func(ctx context.Context, publicKey string) (*WsIdentity, error)
+
+	// AuthenticateReq body of the system/authenticate message: the welcome values signed by the client
+	AuthenticateReq struct {
+		ConnID     string `json:"connId"`
+		ServerTime int64  `json:"serverTime"`
+	}
+
+	AuthenticateResp struct {
+		UserID string         `json:"userId"`
+		Role   string         `json:"role"`
+		Code   Code           `json:"code"`
+		Error  *ErrorResponse `json:"error,omitempty"`
+	}
+)
+
+// SetAuthenticator set the user lookup used by the system/authenticate handshake
+func (ws *WsConn) SetAuthenticator(authenticate AuthenticateFunc) {
+	ws.authenticate = authenticate
+}
+
+// SendToUser queue the message on every connection of the user
+func (ws *WsConn) SendToUser(ctx context.Context, userID string, m cryptographer.Message) error {
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return ws.connectionManager.SendWhere(ctx, raw, func(c *WsConnection) bool {
+		return c.UserID == userID
+	})
+}
+
+// BroadcastToRole queue the message on every connection authenticated with the role
+func (ws *WsConn) BroadcastToRole(ctx context.Context, role string, m cryptographer.Message) {
+	raw, err := json.Marshal(m)
+	if err != nil {
+		ws.log.ErrorContext(ctx, "cannot marshal message for broadcast", "role", role, "err", err)
+		return
+	}
+
+	_ = ws.connectionManager.SendWhere(ctx, raw, func(c *WsConnection) bool {
+		return c.Role == role
+	})
+}
+
+// DisconnectKey close every connection authenticated with the public key
+func (ws *WsConn) DisconnectKey(publicKey string) {
+	ws.connectionManager.CloseWhere(websocket.StatusPolicyViolation, "key revoked", func(c *WsConnection) bool {
+		return c.PublicKey == publicKey
+	})
+}
+
+// handleAuthenticate bind the connection to the user signing the welcome ConnID and ServerTime.
+// A bound connection is never rebound: its subscriptions and presence belong to the first identity
+func (ws *WsConn) handleAuthenticate(ctx context.Context, conn *WsConnection, message cryptographer.Message) {
+	meta := message.Metadata
+
+	if ws.connectionManager.PublicKey(conn.ID) != "" {
+		_ = ws.ReplyError(ctx, conn.ID, meta, errAlreadyAuthenticated())
+		return
+	}
+
+	valid, err := message.Verify()
+	if err != nil || !valid {
+		RecordSignatureFailure("ws")
+		_ = ws.ReplyError(ctx, conn.ID, meta, NewError(Unauthenticated, "ws.badSignature", "invalid envelope signature"))
+		return
+	}
+
+	var req AuthenticateReq
+	if err = json.Unmarshal(message.Body, &req); err != nil {
+		_ = ws.ReplyError(ctx, conn.ID, meta, NewError(InvalidRequest, "ws.badAuthenticate", "cannot decode authenticate request").WithCause(err))
+		return
+	}
+
+	if req.ConnID != conn.ID || req.ServerTime != conn.WelcomeTime {
+		_ = ws.ReplyError(ctx, conn.ID, meta, NewError(Unauthenticated, "ws.badChallenge", "signed welcome does not match the connection"))
+		return
+	}
+
+	if ws.authenticate == nil {
+		_ = ws.ReplyError(ctx, conn.ID, meta, NewError(Unimplemented, "ws.authDisabled", "websocket authentication is not enabled"))
+		return
+	}
+
+	publicKey := hex.EncodeToString(message.PublicKey[:])
+	identity, err := ws.authenticate(ctx, publicKey)
+	if err != nil {
+		_ = ws.ReplyError(ctx, conn.ID, meta, err)
+		return
+	}
+
+	// Checked again, another authenticate of the connection may have bound it meanwhile
+	if !ws.connectionManager.Bind(conn.ID, publicKey, *identity) {
+		_ = ws.ReplyError(ctx, conn.ID, meta, errAlreadyAuthenticated())
+		return
+	}
+	ws.notifyPresence(ctx, conn.ID, *identity, true)
+
+	msg, err := cryptographer.Encode(ws.signer, cryptographer.Metadata{
+		Domain:        meta.Domain,
+		Action:        meta.Action,
+		CorrelationID: meta.CorrelationID,
+	}, AuthenticateResp{
+		UserID: identity.UserID,
+		Role:   identity.Role,
+		Code:   OK,
+	})
+	if err != nil {
+		ws.log.ErrorContext(ctx, "cannot encode authenticate reply", "err", err)
+		return
+	}
+
+	if err = ws.SendTo(ctx, conn.ID, *msg); err != nil {
+		ws.log.ErrorContext(ctx, "cannot send authenticate reply", "err", err)
+	}
+}
+
+func errAlreadyAuthenticated() error {
+	return NewError(FailedPrecondition, "ws.alreadyAuthenticated", "connection already authenticated, open a new one to change user")
+}
+
+// revalidate check periodically that the key bound to the connection is still valid.
+// The connection is closed once the key is revoked
+func (ws *WsConn) revalidate(ctx context.Context, conn *WsConnection, interval time.Duration) {
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+			publicKey := ws.connectionManager.PublicKey(conn.ID)
+			if publicKey == "" || ws.authenticate == nil {
+				continue
+			}
+
+			_, err := ws.authenticate(ctx, publicKey)
+
+			var e *Error
+			if errors.As(err, &e) && e.Code == Unauthenticated {
+				ws.log.InfoContext(ctx, "closing connection of revoked key")
+				ws.DisconnectKey(publicKey)
+				return
+			}
+		}
+	}
+}
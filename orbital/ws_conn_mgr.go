@@ -4,37 +4,233 @@ import (
 	"context"
 	"fmt"
 	"orbital/pkg/stringer"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/coder/websocket"
 )
 
+// OverflowPolicy what happens when a connection send queue is full
+type OverflowPolicy int
+
+const (
+	// OverflowDrop drop the message and keep the connection
+	OverflowDrop OverflowPolicy = iota
+	// OverflowClose close the connection. The client is expected to reconnect
+	OverflowClose
+)
+
+// WsTransport the socket a connection writes to: a websocket or an SSE stream
+type WsTransport interface {
+	Write(ctx context.Context, typ websocket.MessageType, p []byte) error
+	Close(code websocket.StatusCode, reason string) error
+	CloseNow() error
+}
+
+// WsQueueConfig outbound queue settings applied to new connections
+type WsQueueConfig struct {
+	Size         int
+	Policy       OverflowPolicy
+	WriteTimeout time.Duration
+}
+
+// DefaultWsQueueConfig queue settings of the connections unless SetQueueConfig is called
+func DefaultWsQueueConfig() WsQueueConfig {
+	return WsQueueConfig{
+		Size:         64,
+		Policy:       OverflowDrop,
+		WriteTimeout: 5 * time.Second,
+	}
+}
+
 type WsConnection struct {
-	ID     string
-	Conn   *websocket.Conn
-	UserID string // Custom set by the user
+	ID          string
+	Conn        WsTransport
+	UserID      string // Custom set by the user
+	PublicKey   string // Set by the system/authenticate handshake
+	Role        string
+	RemoteAddr  string
+	ConnectedAt time.Time
+	WelcomeTime int64 // ServerTime sent in the welcome message, signed back by the client
+
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+	policy    OverflowPolicy
+	handleMu  sync.Mutex // Serializes the messages POSTed to an event stream
+
+	messagesIn  atomic.Uint64
+	bytesIn     atomic.Uint64
+	messagesOut atomic.Uint64
+	bytesOut    atomic.Uint64
+	dropped     atomic.Uint64
+	lastPong    atomic.Int64 // Unix nano of the last answered ping
+
+	// Set by WsConn when the connection opens
+	ctx    context.Context
+	cancel context.CancelFunc
+	calls  *wsCalls
+}
+
+// WsConnectionStats per connection counters
+type WsConnectionStats struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"userId"`
+	Role        string    `json:"role"`
+	RemoteAddr  string    `json:"remoteAddr"`
+	ConnectedAt time.Time `json:"connectedAt"`
+	LastPong    time.Time `json:"lastPong"`
+	Queued      int       `json:"queued"`
+	MessagesIn  uint64    `json:"messagesIn"`
+	BytesIn     uint64    `json:"bytesIn"`
+	MessagesOut uint64    `json:"messagesOut"`
+	BytesOut    uint64    `json:"bytesOut"`
+	Dropped     uint64    `json:"dropped"`
+}
+
+// enqueue queue the message for the writer. It never blocks
+func (c *WsConnection) enqueue(message []byte) error {
+	select {
+	case <-c.done:
+		return ErrConnClosed
+	default:
+	}
+
+	select {
+	case c.send <- message:
+		return nil
+	default:
+	}
+
+	c.dropped.Add(1)
+	wsDropped.Inc()
+	if c.policy == OverflowClose {
+		// Close waits for the close handshake, don't hold the caller
+		go func() { _ = c.Conn.Close(websocket.StatusPolicyViolation, "send queue overflow") }()
+		c.stop()
+	}
+
+	return ErrSendQueueFull
+}
+
+// RecordPong mark the connection alive
+func (c *WsConnection) RecordPong() {
+	c.lastPong.Store(time.Now().UnixNano())
+}
+
+// RecordIn count a message received on the connection
+func (c *WsConnection) RecordIn(size int) {
+	c.messagesIn.Add(1)
+	c.bytesIn.Add(uint64(size))
+	wsMessages.Inc("in")
+	wsBytes.Add(float64(size), "in")
+}
+
+func (c *WsConnection) Stats() WsConnectionStats {
+	return WsConnectionStats{
+		ID:          c.ID,
+		UserID:      c.UserID,
+		Role:        c.Role,
+		RemoteAddr:  c.RemoteAddr,
+		ConnectedAt: c.ConnectedAt,
+		LastPong:    time.Unix(0, c.lastPong.Load()),
+		Queued:      len(c.send),
+		MessagesIn:  c.messagesIn.Load(),
+		BytesIn:     c.bytesIn.Load(),
+		MessagesOut: c.messagesOut.Load(),
+		BytesOut:    c.bytesOut.Load(),
+		Dropped:     c.dropped.Load(),
+	}
+}
+
+func (c *WsConnection) stop() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// writer the only goroutine writing to the socket. A failed write closes the connection
+func (c *WsConnection) writer(writeTimeout time.Duration) {
+	for {
+		select {
+		case <-c.done:
+			return
+		case message := <-c.send:
+			ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+			err := c.Conn.Write(ctx, websocket.MessageBinary, message)
+			cancel()
+
+			if err != nil {
+				_ = c.Conn.CloseNow()
+				c.stop()
+				return
+			}
+
+			c.messagesOut.Add(1)
+			c.bytesOut.Add(uint64(len(message)))
+			wsMessages.Inc("out")
+			wsBytes.Add(float64(len(message)), "out")
+		}
+	}
 }
 
 type WsConnectionManager struct {
 	mu          sync.RWMutex
 	connections map[string]*WsConnection
+	queue       WsQueueConfig
 }
 
-func (wcm *WsConnectionManager) AddConnection(id string, conn *websocket.Conn) {
+// SetQueueConfig set the queue settings of the connections added afterward
+func (wcm *WsConnectionManager) SetQueueConfig(cfg WsQueueConfig) {
 	wcm.mu.Lock()
 	defer wcm.mu.Unlock()
-	wcm.connections[id] = &WsConnection{
-		ID:   id,
-		Conn: conn,
+
+	if cfg.Size <= 0 {
+		cfg.Size = wcm.queue.Size
 	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = wcm.queue.WriteTimeout
+	}
+
+	wcm.queue = cfg
 }
 
-func (wcm *WsConnectionManager) RemoveConnection(id string) {
+func (wcm *WsConnectionManager) AddConnection(id string, conn WsTransport, remoteAddr string) *WsConnection {
 	wcm.mu.Lock()
 	defer wcm.mu.Unlock()
 
+	now := time.Now()
+	c := &WsConnection{
+		ID:          id,
+		Conn:        conn,
+		RemoteAddr:  remoteAddr,
+		ConnectedAt: now,
+		WelcomeTime: now.Unix(),
+		send:        make(chan []byte, wcm.queue.Size),
+		done:        make(chan struct{}),
+		policy:      wcm.queue.Policy,
+	}
+	c.lastPong.Store(now.UnixNano())
+	wcm.connections[id] = c
+	wsConnections.Inc()
+
+	go c.writer(wcm.queue.WriteTimeout)
+
+	return c
+}
+
+// RemoveConnection forget the connection and stop its writer. Queued messages are discarded
+func (wcm *WsConnectionManager) RemoveConnection(id string) {
+	wcm.mu.Lock()
+	c, ok := wcm.connections[id]
 	delete(wcm.connections, id)
+	wcm.mu.Unlock()
+
+	if ok {
+		wsConnections.Dec()
+		c.stop()
+	}
 }
 
 func (wcm *WsConnectionManager) GetConnection(id string) (*WsConnection, bool) {
@@ -54,31 +250,155 @@ func (wcm *WsConnectionManager) SetUserID(id, userID string) {
 	}
 }
 
-func (wcm *WsConnectionManager) Broadcast(ctx context.Context, message []byte) {
+// Bind attach the authenticated identity to the connection.
+// Returns false if the connection is unknown or already bound, an identity is never replaced
+func (wcm *WsConnectionManager) Bind(id, publicKey string, identity WsIdentity) bool {
+	wcm.mu.Lock()
+	defer wcm.mu.Unlock()
+
+	c, exists := wcm.connections[id]
+	if !exists || c.PublicKey != "" {
+		return false
+	}
+
+	c.PublicKey = publicKey
+	c.UserID = identity.UserID
+	c.Role = identity.Role
+
+	return true
+}
+
+// PublicKey return the key bound to the connection. Empty if not authenticated
+func (wcm *WsConnectionManager) PublicKey(id string) string {
+	wcm.mu.RLock()
+	defer wcm.mu.RUnlock()
+
+	if c, exists := wcm.connections[id]; exists {
+		return c.PublicKey
+	}
+
+	return ""
+}
+
+// SendWhere queue the message on the connections matching the filter.
+// ErrConnNotFound is returned when none matches
+func (wcm *WsConnectionManager) SendWhere(ctx context.Context, message []byte, match func(c *WsConnection) bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	conns := wcm.filter(match)
+	if len(conns) == 0 {
+		return ErrConnNotFound
+	}
+
+	for _, c := range conns {
+		_ = c.enqueue(message)
+	}
+
+	return nil
+}
+
+// CloseWhere close the connections matching the filter
+func (wcm *WsConnectionManager) CloseWhere(code websocket.StatusCode, reason string, match func(c *WsConnection) bool) {
+	for _, c := range wcm.filter(match) {
+		go func() { _ = c.Conn.Close(code, reason) }()
+		c.stop()
+	}
+}
+
+// Identity return the identity bound to the connection. Nil if not authenticated
+func (wcm *WsConnectionManager) Identity(id string) *WsIdentity {
 	wcm.mu.RLock()
 	defer wcm.mu.RUnlock()
 
-	for id, conn := range wcm.connections {
-		if err := conn.Conn.Write(ctx, websocket.MessageBinary, message); err != nil {
-			// TODO: Check if this is a correct approach
-			wcm.RemoveConnection(id)
+	c, exists := wcm.connections[id]
+	if !exists || c.PublicKey == "" {
+		return nil
+	}
+
+	return &WsIdentity{UserID: c.UserID, Role: c.Role}
+}
+
+// OnlineUsers return the sorted ids of the authenticated users
+func (wcm *WsConnectionManager) OnlineUsers() []string {
+	wcm.mu.RLock()
+	defer wcm.mu.RUnlock()
+
+	seen := make(map[string]struct{})
+	for _, c := range wcm.connections {
+		if c.UserID != "" {
+			seen[c.UserID] = struct{}{}
 		}
 	}
+
+	users := make([]string, 0, len(seen))
+	for u := range seen {
+		users = append(users, u)
+	}
+	sort.Strings(users)
+
+	return users
+}
+
+// Broadcast queue the message on every connection. Slow connections don't hold the others
+func (wcm *WsConnectionManager) Broadcast(_ context.Context, message []byte) {
+	for _, c := range wcm.snapshot() {
+		_ = c.enqueue(message)
+	}
 }
 
+// SendTo queue the message on the connection
 func (wcm *WsConnectionManager) SendTo(ctx context.Context, id string, message []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	conn, exists := wcm.GetConnection(id)
 	if !exists {
-		return fmt.Errorf("connection not found for id: %s", id)
+		return fmt.Errorf("%w:[%s]", ErrConnNotFound, id)
+	}
+
+	return conn.enqueue(message)
+}
+
+// Stats return the counters of every connection
+func (wcm *WsConnectionManager) Stats() []WsConnectionStats {
+	wcm.mu.RLock()
+	defer wcm.mu.RUnlock()
+
+	stats := make([]WsConnectionStats, 0, len(wcm.connections))
+	for _, c := range wcm.connections {
+		stats = append(stats, c.Stats())
+	}
+
+	return stats
+}
+
+func (wcm *WsConnectionManager) snapshot() []*WsConnection {
+	return wcm.filter(func(*WsConnection) bool { return true })
+}
+
+// filter return the connections matching. The identity fields are read under the lock
+func (wcm *WsConnectionManager) filter(match func(c *WsConnection) bool) []*WsConnection {
+	wcm.mu.RLock()
+	defer wcm.mu.RUnlock()
+
+	var conns []*WsConnection
+	for _, c := range wcm.connections {
+		if match(c) {
+			conns = append(conns, c)
+		}
 	}
 
-	return conn.Conn.Write(ctx, websocket.MessageBinary, message)
+	return conns
 }
 
 // NewWsConnectionManager create a new connection manager
 func NewWsConnectionManager() *WsConnectionManager {
 	return &WsConnectionManager{
 		connections: make(map[string]*WsConnection),
+		queue:       DefaultWsQueueConfig(),
 	}
 }
 
@@ -0,0 +1,244 @@
+package orbital
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// fakeTransport records the writes. Write blocks while block is open and fails with err when set
+type fakeTransport struct {
+	mu      sync.Mutex
+	writes  [][]byte
+	err     error
+	block   chan struct{}
+	writing chan struct{} // Signaled when a Write starts
+	written chan []byte   // Signaled when a Write succeeds
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newFakeTransport() *fakeTransport {
+	return &fakeTransport{
+		writing: make(chan struct{}, 128),
+		written: make(chan []byte, 128),
+		closed:  make(chan struct{}),
+	}
+}
+
+func (t *fakeTransport) Write(ctx context.Context, _ websocket.MessageType, p []byte) error {
+	t.writing <- struct{}{}
+
+	if t.block != nil {
+		select {
+		case <-t.block:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if t.err != nil {
+		return t.err
+	}
+
+	t.mu.Lock()
+	t.writes = append(t.writes, p)
+	t.mu.Unlock()
+	t.written <- p
+
+	return nil
+}
+
+func (t *fakeTransport) Close(websocket.StatusCode, string) error {
+	t.closeOnce.Do(func() { close(t.closed) })
+	return nil
+}
+
+func (t *fakeTransport) CloseNow() error {
+	return t.Close(websocket.StatusGoingAway, "")
+}
+
+func (t *fakeTransport) isClosed() bool {
+	select {
+	case <-t.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func newTestManager(size int, policy OverflowPolicy) *WsConnectionManager {
+	wcm := NewWsConnectionManager()
+	wcm.SetQueueConfig(WsQueueConfig{Size: size, Policy: policy, WriteTimeout: 5 * time.Second})
+	return wcm
+}
+
+func waitFor(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timeout waiting for %s", what)
+	}
+}
+
+// fillQueue block the writer on a first message then fill the queue
+func fillQueue(t *testing.T, wcm *WsConnectionManager, tr *fakeTransport, size int) {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := wcm.SendTo(ctx, "c1", []byte("first")); err != nil {
+		t.Fatalf("first send: %v", err)
+	}
+	waitFor(t, tr.writing, "the writer to pick the first message")
+
+	for i := 0; i < size; i++ {
+		if err := wcm.SendTo(ctx, "c1", []byte("queued")); err != nil {
+			t.Fatalf("send %d: %v", i, err)
+		}
+	}
+}
+
+func TestEnqueueOverflowDrop(t *testing.T) {
+	wcm := newTestManager(2, OverflowDrop)
+	tr := newFakeTransport()
+	tr.block = make(chan struct{})
+	defer close(tr.block)
+
+	conn := wcm.AddConnection("c1", tr, "127.0.0.1:1")
+	fillQueue(t, wcm, tr, 2)
+
+	if err := wcm.SendTo(context.Background(), "c1", []byte("overflow")); !errors.Is(err, ErrSendQueueFull) {
+		t.Fatalf("want ErrSendQueueFull, got %v", err)
+	}
+
+	if dropped := conn.Stats().Dropped; dropped != 1 {
+		t.Fatalf("want 1 dropped, got %d", dropped)
+	}
+	if _, found := wcm.GetConnection("c1"); !found {
+		t.Fatal("connection removed on drop")
+	}
+	if tr.isClosed() {
+		t.Fatal("transport closed on drop")
+	}
+
+	// The queue drains once the consumer catches up, the connection is still usable
+	tr.block <- struct{}{}
+	waitFor(t, tr.writing, "the next write")
+	if err := wcm.SendTo(context.Background(), "c1", []byte("after")); err != nil {
+		t.Fatalf("send after drain: %v", err)
+	}
+}
+
+func TestEnqueueOverflowClose(t *testing.T) {
+	wcm := newTestManager(1, OverflowClose)
+	tr := newFakeTransport()
+	tr.block = make(chan struct{})
+	defer close(tr.block)
+
+	conn := wcm.AddConnection("c1", tr, "127.0.0.1:1")
+	fillQueue(t, wcm, tr, 1)
+
+	if err := wcm.SendTo(context.Background(), "c1", []byte("overflow")); !errors.Is(err, ErrSendQueueFull) {
+		t.Fatalf("want ErrSendQueueFull, got %v", err)
+	}
+
+	waitFor(t, tr.closed, "the transport to close")
+
+	if err := conn.enqueue([]byte("late")); !errors.Is(err, ErrConnClosed) {
+		t.Fatalf("want ErrConnClosed after overflow, got %v", err)
+	}
+}
+
+func TestWriterStopsOnWriteError(t *testing.T) {
+	wcm := newTestManager(4, OverflowDrop)
+	tr := newFakeTransport()
+	tr.err = errors.New("broken pipe")
+
+	conn := wcm.AddConnection("c1", tr, "127.0.0.1:1")
+
+	if err := wcm.SendTo(context.Background(), "c1", []byte("fails")); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	waitFor(t, tr.closed, "the transport to close")
+	waitFor(t, conn.done, "the writer to stop")
+
+	if err := conn.enqueue([]byte("late")); !errors.Is(err, ErrConnClosed) {
+		t.Fatalf("want ErrConnClosed after a failed write, got %v", err)
+	}
+
+	select {
+	case <-tr.writing:
+	default:
+		t.Fatal("write not attempted")
+	}
+	select {
+	case <-tr.writing:
+		t.Fatal("writer kept writing after a failure")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestBroadcastNotStalledBySlowConsumer(t *testing.T) {
+	const size, messages = 4, 20
+
+	wcm := newTestManager(size, OverflowDrop)
+
+	slow := newFakeTransport()
+	slow.block = make(chan struct{})
+	defer close(slow.block)
+
+	fast := newFakeTransport()
+
+	slowConn := wcm.AddConnection("slow", slow, "127.0.0.1:1")
+	wcm.AddConnection("fast", fast, "127.0.0.1:2")
+
+	for i := 0; i < messages; i++ {
+		done := make(chan struct{})
+		go func() {
+			wcm.Broadcast(context.Background(), []byte{byte(i)})
+			close(done)
+		}()
+		waitFor(t, done, "Broadcast to return")
+
+		select {
+		case got := <-fast.written:
+			if got[0] != byte(i) {
+				t.Fatalf("fast consumer got message %d, want %d", got[0], i)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("fast consumer did not get message %d", i)
+		}
+	}
+
+	if dropped := slowConn.Stats().Dropped; dropped == 0 {
+		t.Fatal("slow consumer dropped nothing")
+	}
+}
+
+func TestBindKeepsFirstIdentity(t *testing.T) {
+	wcm := newTestManager(4, OverflowDrop)
+	wcm.AddConnection("c1", newFakeTransport(), "127.0.0.1:1")
+
+	if !wcm.Bind("c1", "admin-key", WsIdentity{UserID: "u1", Role: "admin"}) {
+		t.Fatal("first bind refused")
+	}
+	if wcm.Bind("c1", "user-key", WsIdentity{UserID: "u2", Role: "user"}) {
+		t.Fatal("bound connection rebound")
+	}
+
+	conn, _ := wcm.GetConnection("c1")
+	if conn.PublicKey != "admin-key" || conn.UserID != "u1" || conn.Role != "admin" {
+		t.Fatalf("identity replaced: %s %s %s", conn.PublicKey, conn.UserID, conn.Role)
+	}
+
+	if wcm.Bind("missing", "key", WsIdentity{}) {
+		t.Fatal("unknown connection bound")
+	}
+}
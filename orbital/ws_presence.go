@@ -0,0 +1,61 @@
+package orbital
+
+import (
+	"context"
+
+	"github.com/coder/websocket"
+)
+
+type (
+	// PresenceEvent an authenticated connection joined or left
+	PresenceEvent struct {
+		ConnID string `json:"connId"`
+		UserID string `json:"userId"`
+		Role   string `json:"role"`
+		Online bool   `json:"online"`
+	}
+
+	// PresenceFunc called on every PresenceEvent
+	PresenceFunc func(ctx context.Context, event PresenceEvent)
+)
+
+// OnPresence register a hook called when an authenticated connection joins or leaves
+func (ws *WsConn) OnPresence(fn PresenceFunc) {
+	ws.presence = append(ws.presence, fn)
+}
+
+// OnlineUsers return the ids of the users with at least one authenticated connection
+func (ws *WsConn) OnlineUsers() []string {
+	return ws.connectionManager.OnlineUsers()
+}
+
+// Kick close a connection. The client is told the reason in the close frame
+func (ws *WsConn) Kick(connID, reason string) error {
+	if _, found := ws.connectionManager.GetConnection(connID); !found {
+		return NewError(NotFound, "ws.connNotFound", "connection not found").
+			WithDetails(map[string]any{"connId": connID})
+	}
+
+	if reason == "" {
+		reason = "kicked"
+	}
+
+	ws.connectionManager.CloseWhere(websocket.StatusPolicyViolation, reason, func(c *WsConnection) bool {
+		return c.ID == connID
+	})
+
+	return nil
+}
+
+func (ws *WsConn) notifyPresence(ctx context.Context, connID string, identity WsIdentity, online bool) {
+	event := PresenceEvent{
+		ConnID: connID,
+		UserID: identity.UserID,
+		Role:   identity.Role,
+		Online: online,
+	}
+
+	for _, fn := range ws.presence {
+		fn(ctx, event)
+	}
+}
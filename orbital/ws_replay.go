@@ -0,0 +1,188 @@
+package orbital
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"orbital/pkg/cryptographer"
+	"strconv"
+	"sync"
+)
+
+// seqTag metadata tag holding the sequence number of a published message
+const seqTag = "seq"
+
+// ReplayStore bounded log of the messages published on each topic
+type ReplayStore interface {
+	Append(topic string, seq uint64, message []byte) error
+	// Since return the messages after seq in order. ok is false when the log no longer holds all of them
+	Since(topic string, seq uint64) (messages [][]byte, ok bool, err error)
+	// Last return the last sequence number of the topic, zero if none
+	Last(topic string) (uint64, error)
+}
+
+type replayEntry struct {
+	seq     uint64
+	message []byte
+}
+
+// MemoryReplayStore keeps the last size messages of each topic in memory
+type MemoryReplayStore struct {
+	mu     sync.RWMutex
+	size   int
+	topics map[string][]replayEntry
+}
+
+func NewMemoryReplayStore(size int) *MemoryReplayStore {
+	return &MemoryReplayStore{
+		size:   size,
+		topics: make(map[string][]replayEntry),
+	}
+}
+
+func (s *MemoryReplayStore) Append(topic string, seq uint64, message []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := append(s.topics[topic], replayEntry{seq: seq, message: message})
+	if len(entries) > s.size {
+		entries = entries[len(entries)-s.size:]
+	}
+	s.topics[topic] = entries
+
+	return nil
+}
+
+func (s *MemoryReplayStore) Since(topic string, seq uint64) ([][]byte, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := s.topics[topic]
+	if len(entries) == 0 {
+		return nil, seq == 0, nil
+	}
+
+	last := entries[len(entries)-1].seq
+	if seq > last || entries[0].seq > seq+1 {
+		return nil, false, nil
+	}
+
+	var messages [][]byte
+	for _, e := range entries {
+		if e.seq > seq {
+			messages = append(messages, e.message)
+		}
+	}
+
+	return messages, true, nil
+}
+
+func (s *MemoryReplayStore) Last(topic string) (uint64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := s.topics[topic]
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	return entries[len(entries)-1].seq, nil
+}
+
+// SetReplayStore set the log used to replay the messages a reconnecting client missed
+func (ws *WsConn) SetReplayStore(store ReplayStore) {
+	ws.publishMu.Lock()
+	defer ws.publishMu.Unlock()
+
+	ws.replay = store
+	ws.seqs = make(map[string]uint64)
+}
+
+// Publish number the message, keep it in the replay log and queue it on the connections subscribed to the topic
+func (ws *WsConn) Publish(ctx context.Context, topic string, m cryptographer.Message) {
+	ws.publishMu.Lock()
+	defer ws.publishMu.Unlock()
+
+	seq, err := ws.nextSeq(topic)
+	if err != nil {
+		ws.log.ErrorContext(ctx, "cannot read topic sequence", "topic", topic, "err", err)
+		return
+	}
+
+	// The caller may reuse the message or publish it on several topics, its tags are not touched
+	tags := make(map[string]string, len(m.Metadata.Tags)+1)
+	maps.Copy(tags, m.Metadata.Tags)
+	m.Metadata.Tags = tags
+	m.Metadata.Tags[seqTag] = strconv.FormatUint(seq, 10)
+
+	if err = m.SignWith(ws.signer); err != nil {
+		ws.log.ErrorContext(ctx, "cannot sign published message", "topic", topic, "err", err)
+		return
+	}
+
+	raw, err := json.Marshal(m)
+	if err != nil {
+		ws.log.ErrorContext(ctx, "cannot marshal message for publish", "topic", topic, "err", err)
+		return
+	}
+
+	if err = ws.replay.Append(topic, seq, raw); err != nil {
+		ws.log.ErrorContext(ctx, "cannot append to replay log", "topic", topic, "err", err)
+	}
+
+	for _, connID := range ws.subscriptions.Subscribers(topic) {
+		if err = ws.connectionManager.SendTo(ctx, connID, raw); err != nil {
+			ws.log.ErrorContext(ctx, "cannot publish message", "topic", topic, "subscriber", connID, "err", err)
+		}
+	}
+}
+
+// HasSubscribers report whether a connection is subscribed to the topic. Publishers skip costly work otherwise
+func (ws *WsConn) HasSubscribers(topic string) bool {
+	return len(ws.subscriptions.Subscribers(topic)) > 0
+}
+
+// nextSeq increment the topic sequence. The first call resumes from the replay log
+func (ws *WsConn) nextSeq(topic string) (uint64, error) {
+	seq, found := ws.seqs[topic]
+	if !found {
+		last, err := ws.replay.Last(topic)
+		if err != nil {
+			return 0, err
+		}
+		seq = last
+	}
+
+	seq++
+	ws.seqs[topic] = seq
+
+	return seq, nil
+}
+
+// replaySince queue the messages of each topic after the client last seen sequence.
+// Returns the topics the client must resync because the log was truncated.
+// Must hold publishMu so replayed and live messages stay in order
+func (ws *WsConn) replaySince(ctx context.Context, connID string, since map[string]uint64) []string {
+	var resync []string
+	for topic, seq := range since {
+		messages, ok, err := ws.replay.Since(topic, seq)
+		if err != nil {
+			ws.log.ErrorContext(ctx, "cannot read replay log", "topic", topic, "err", err)
+		}
+
+		if err != nil || !ok {
+			resync = append(resync, topic)
+			continue
+		}
+
+		for _, raw := range messages {
+			if err = ws.connectionManager.SendTo(ctx, connID, raw); err != nil {
+				ws.log.ErrorContext(ctx, "cannot replay message", "topic", topic, "err", err)
+				resync = append(resync, topic)
+				break
+			}
+		}
+	}
+
+	return resync
+}
@@ -0,0 +1,188 @@
+package orbital
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"orbital/pkg/cryptographer"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// rpcDomain messages with this domain are routed to the HTTP routes: rpc/<Service>/<Action>
+	rpcDomain = "rpc"
+
+	// rpcTimeoutTag metadata tag holding the call timeout in milliseconds
+	rpcTimeoutTag = "timeout"
+
+	// cancelTopic cancel an in-flight call identified by the message correlation id
+	cancelTopic = "system/cancel"
+)
+
+type ctxKey string
+
+const (
+	correlationIDCtxKey ctxKey = "correlationId"
+	connIDCtxKey        ctxKey = "connId"
+	permissionCtxKey    ctxKey = "permission"
+	requestStateCtxKey  ctxKey = "requestState"
+	adminPeerCtxKey     ctxKey = "adminPeer"
+)
+
+// CorrelationID return the correlation id of the call carried by the context
+func CorrelationID(ctx context.Context) string {
+	cid, _ := ctx.Value(correlationIDCtxKey).(string)
+	return cid
+}
+
+// ConnID return the websocket connection id of the call carried by the context
+func ConnID(ctx context.Context) string {
+	connID, _ := ctx.Value(connIDCtxKey).(string)
+	return connID
+}
+
+// wsCalls in-flight calls of a connection indexed by correlation id
+type wsCalls struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+	slots   chan struct{}
+}
+
+func newWsCalls(maxInflight int) *wsCalls {
+	return &wsCalls{
+		cancels: make(map[string]context.CancelFunc),
+		slots:   make(chan struct{}, maxInflight),
+	}
+}
+
+// start reserve a slot for the call. Fails when the correlation id is already in flight
+// or the connection has too many calls in flight
+func (c *wsCalls) start(cid string, cancel context.CancelFunc) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.cancels[cid]; ok {
+		return NewError(InvalidRequest, "ws.duplicateCorrelationId", "a call with this correlation id is already in flight")
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+	default:
+		return NewError(ResourceExhausted, "ws.tooManyCalls", "too many calls in flight")
+	}
+
+	c.cancels[cid] = cancel
+
+	return nil
+}
+
+func (c *wsCalls) done(cid string) {
+	c.mu.Lock()
+	if cancel, ok := c.cancels[cid]; ok {
+		cancel()
+		delete(c.cancels, cid)
+	}
+	c.mu.Unlock()
+
+	<-c.slots
+}
+
+func (c *wsCalls) cancel(cid string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cancel, ok := c.cancels[cid]; ok {
+		cancel()
+	}
+}
+
+// responseRecorder capture the reply of a route called from inside the node: over the websocket or in a batch
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseRecorder) Header() http.Header {
+	return w.header
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func (w *responseRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// SetRPCHandler set the handler used to serve calls made over the websocket
+func (ws *WsConn) SetRPCHandler(h http.Handler) {
+	ws.rpcHandler = h
+}
+
+// handleCall run an HTTP route for a websocket message and send back its signed reply.
+// The reply carries the request correlation id
+func (ws *WsConn) handleCall(connCtx context.Context, connID, remoteAddr string, calls *wsCalls, message cryptographer.Message, raw []byte) {
+	meta := message.Metadata
+	if meta.CorrelationID == "" {
+		_ = ws.ReplyError(connCtx, connID, meta, NewError(InvalidRequest, "ws.correlationIdRequired", "correlation id is required for calls"))
+		return
+	}
+
+	if ws.rpcHandler == nil {
+		_ = ws.ReplyError(connCtx, connID, meta, NewError(Unimplemented, "ws.rpcDisabled", "calls over websocket are not enabled"))
+		return
+	}
+
+	timeout := ws.callTimeout
+	if ms, err := strconv.ParseInt(meta.Tags[rpcTimeoutTag], 10, 64); err == nil && ms > 0 {
+		if requested := time.Duration(ms) * time.Millisecond; requested < timeout {
+			timeout = requested
+		}
+	}
+
+	callCtx, cancel := context.WithTimeout(connCtx, timeout)
+	if err := calls.start(meta.CorrelationID, cancel); err != nil {
+		cancel()
+		_ = ws.ReplyError(connCtx, connID, meta, err)
+		return
+	}
+
+	go func() {
+		defer calls.done(meta.CorrelationID)
+
+		// The correlation id is already carried by connCtx, see handleMessage
+		callCtx = context.WithValue(callCtx, connIDCtxKey, connID)
+
+		req, err := http.NewRequestWithContext(callCtx, http.MethodPost, "/"+rpcDomain+"/"+meta.Action, bytes.NewReader(raw))
+		if err != nil {
+			_ = ws.ReplyError(connCtx, connID, meta, err)
+			return
+		}
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("Content-Type", "application/json")
+
+		w := &responseRecorder{header: make(http.Header)}
+		ws.rpcHandler.ServeHTTP(w, req)
+
+		// Canceled by the client or timed out before the handler replied
+		if w.body.Len() == 0 {
+			if err = callCtx.Err(); err == nil {
+				err = NewError(Internal, "ws.emptyReply", "route did not reply")
+			}
+			_ = ws.ReplyError(connCtx, connID, meta, err)
+			return
+		}
+
+		if err = ws.connectionManager.SendTo(connCtx, connID, w.body.Bytes()); err != nil {
+			ws.log.ErrorContext(connCtx, "cannot send call reply", "err", err)
+		}
+	}()
+}
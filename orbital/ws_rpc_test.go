@@ -0,0 +1,51 @@
+package orbital
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWsCallsDuplicateCorrelationID(t *testing.T) {
+	calls := newWsCalls(4)
+
+	firstCtx, firstCancel := context.WithCancel(context.Background())
+	if err := calls.start("c1", firstCancel); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+
+	_, secondCancel := context.WithCancel(context.Background())
+	defer secondCancel()
+
+	var e *Error
+	if err := calls.start("c1", secondCancel); !errors.As(err, &e) || e.Type != "ws.duplicateCorrelationId" {
+		t.Fatalf("want ws.duplicateCorrelationId, got %v", err)
+	}
+
+	// The rejected call took no slot and left the first one cancelable
+	if len(calls.slots) != 1 {
+		t.Fatalf("want 1 slot in use, got %d", len(calls.slots))
+	}
+	calls.cancel("c1")
+	if firstCtx.Err() == nil {
+		t.Fatal("first call not canceled")
+	}
+
+	calls.done("c1")
+	if err := calls.start("c1", secondCancel); err != nil {
+		t.Fatalf("id reused after done: %v", err)
+	}
+}
+
+func TestWsCallsTooMany(t *testing.T) {
+	calls := newWsCalls(1)
+
+	if err := calls.start("c1", func() {}); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+
+	var e *Error
+	if err := calls.start("c2", func() {}); !errors.As(err, &e) || e.Code != ResourceExhausted {
+		t.Fatalf("want ResourceExhausted, got %v", err)
+	}
+}
@@ -0,0 +1,238 @@
+package orbital
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"orbital/pkg/cryptographer"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// sseConnQuery query parameter naming the stream a POSTed message belongs to
+const sseConnQuery = "conn"
+
+// maxSSEMessageSize upper bound of a message POSTed by a client
+const maxSSEMessageSize = 1 << 20
+
+// sseTransport write the connection messages as Server-Sent Events
+type sseTransport struct {
+	mu     sync.Mutex
+	w      http.ResponseWriter
+	rc     *http.ResponseController
+	closed bool
+	cancel context.CancelFunc // Ends the stream request
+}
+
+func (t *sseTransport) Write(ctx context.Context, _ websocket.MessageType, p []byte) error {
+	return t.write(ctx, "data: %s\n\n", p)
+}
+
+// comment keep proxies from closing an idle stream
+func (t *sseTransport) comment(ctx context.Context, text string) error {
+	return t.write(ctx, ": %s\n\n", text)
+}
+
+func (t *sseTransport) write(ctx context.Context, format string, p any) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return ErrConnClosed
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = t.rc.SetWriteDeadline(deadline)
+	}
+
+	if _, err := fmt.Fprintf(t.w, format, p); err != nil {
+		return err
+	}
+
+	return t.rc.Flush()
+}
+
+func (t *sseTransport) Close(_ websocket.StatusCode, _ string) error {
+	return t.CloseNow()
+}
+
+// CloseNow end the stream. The ResponseWriter is not used once the handler returned
+func (t *sseTransport) CloseNow() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.closed = true
+	t.cancel()
+
+	return nil
+}
+
+// ServeSSE fallback for clients that cannot open a websocket.
+// GET opens the event stream, POST ?conn=<connID> delivers a message signed by the stream key
+func (ws *WsConn) ServeSSE(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		ws.serveStream(w, r)
+	case http.MethodPost:
+		ws.serveStreamMessage(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		ws.replyHTTPError(w, ErrMethodNotAllowed)
+	}
+}
+
+func (ws *WsConn) serveStream(w http.ResponseWriter, r *http.Request) {
+	release, err := ws.limiter.OpenConn(r.RemoteAddr)
+	if err != nil {
+		ws.replyHTTPError(w, err)
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	transport := &sseTransport{
+		w:      w,
+		rc:     http.NewResponseController(w),
+		cancel: cancel,
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err = transport.rc.Flush(); err != nil {
+		ws.log.Error("sse stream not supported", "err", err)
+		return
+	}
+
+	conn, connCtx := ws.openConnection(ctx, transport, r.RemoteAddr)
+	defer func() {
+		ws.closeConnection(conn)
+
+		// The handler returns, no write may happen after this point
+		transport.mu.Lock()
+		transport.closed = true
+		transport.mu.Unlock()
+	}()
+
+	pingInterval := 15 * time.Second
+	tick := time.NewTicker(pingInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-connCtx.Done():
+			return
+		case <-tick.C:
+			pingCtx, pingCancel := context.WithTimeout(connCtx, 5*time.Second)
+			err := transport.comment(pingCtx, "ping")
+			pingCancel()
+
+			if err != nil {
+				return
+			}
+			conn.RecordPong()
+		}
+	}
+}
+
+func (ws *WsConn) serveStreamMessage(w http.ResponseWriter, r *http.Request) {
+	conn, found := ws.connectionManager.GetConnection(r.URL.Query().Get(sseConnQuery))
+	if !found {
+		ws.replyHTTPError(w, NewError(NotFound, "ws.connNotFound", "connection not found"))
+		return
+	}
+
+	if _, ok := conn.Conn.(*sseTransport); !ok {
+		ws.replyHTTPError(w, NewError(InvalidRequest, "ws.notStream", "connection is not an event stream"))
+		return
+	}
+
+	msg, err := io.ReadAll(io.LimitReader(r.Body, maxSSEMessageSize+1))
+	if err != nil {
+		ws.replyHTTPError(w, fmt.Errorf("%w:[%v]", ErrBadPayload, err))
+		return
+	}
+
+	if len(msg) > maxSSEMessageSize {
+		ws.replyHTTPError(w, NewError(ResourceExhausted, "ws.messageTooLarge", "message too large"))
+		return
+	}
+
+	var message cryptographer.Message
+	if err = json.Unmarshal(msg, &message); err != nil {
+		ws.replyHTTPError(w, fmt.Errorf("%w:[%v]", ErrBadPayload, err))
+		return
+	}
+
+	if err = ws.authorizeStreamPost(conn, message); err != nil {
+		ws.replyHTTPError(w, err)
+		return
+	}
+
+	// Replies are pushed on the stream
+	w.WriteHeader(http.StatusAccepted)
+
+	// One message at a time per connection, as the websocket read loop does
+	conn.handleMu.Lock()
+	defer conn.handleMu.Unlock()
+
+	ws.handleMessage(conn, msg)
+}
+
+// authorizeStreamPost check the message is signed by the key bound to the stream. The connection id
+// is not a credential, so before the handshake only system/authenticate is accepted: its body signs the id
+func (ws *WsConn) authorizeStreamPost(conn *WsConnection, message cryptographer.Message) error {
+	valid, err := message.Verify()
+	if err != nil || !valid {
+		RecordSignatureFailure("sse")
+		return NewError(Unauthenticated, "ws.badSignature", "invalid envelope signature")
+	}
+
+	publicKey := ws.connectionManager.PublicKey(conn.ID)
+	if publicKey == "" {
+		if message.Metadata.Domain+"/"+message.Metadata.Action != authenticateTopic {
+			return NewError(Unauthenticated, "ws.notAuthenticated", "authenticate the stream first")
+		}
+		return nil
+	}
+
+	if publicKey != hex.EncodeToString(message.PublicKey[:]) {
+		return NewError(Unauthenticated, "ws.keyMismatch", "message not signed by the stream key")
+	}
+
+	return nil
+}
+
+// replyHTTPError answer a stream request that failed with a signed ErrorReply
+func (ws *WsConn) replyHTTPError(w http.ResponseWriter, err error) {
+	e := AsError(err)
+
+	ws.log.Error("sse message rejected", "code", e.Code.String(), "err", e.logCause(err))
+
+	if e.retry > 0 {
+		w.Header().Set("Retry-After", retryAfterSeconds(e.retry))
+	}
+
+	msg, encErr := cryptographer.Encode(ws.signer, cryptographer.Metadata{
+		Domain: "system",
+		Action: "error",
+	}, e.Reply())
+	if encErr != nil {
+		http.Error(w, e.Msg, e.Code.HTTPStatus())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.Code.HTTPStatus())
+	_ = json.NewEncoder(w).Encode(msg)
+}
@@ -0,0 +1,298 @@
+package orbital
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"orbital/pkg/cryptographer"
+	"path"
+	"sort"
+	"sync"
+)
+
+const (
+	subscribeTopic   = "system/subscribe"
+	unsubscribeTopic = "system/unsubscribe"
+)
+
+type (
+	// AuthorizeFunc check that the owner of the public key holds the permission
+	AuthorizeFunc func(ctx context.Context, publicKey, permission string) error
+
+	// SubscribeReq body of system/subscribe and system/unsubscribe messages.
+	// Topics are patterns: "machine/jobAllData", "machine/*"
+	// Since holds the last sequence seen per topic by a reconnecting client, the missed messages are replayed
+	SubscribeReq struct {
+		Topics []string          `json:"topics"`
+		Since  map[string]uint64 `json:"since,omitempty"`
+	}
+
+	// SubscribeResp the patterns the connection is subscribed to after the change
+	// Resync lists the topics that could not be replayed, the client must reload their state
+	SubscribeResp struct {
+		Topics []string       `json:"topics"`
+		Resync []string       `json:"resync,omitempty"`
+		Code   Code           `json:"code"`
+		Error  *ErrorResponse `json:"error,omitempty"`
+	}
+)
+
+// WsSubscriptions topic patterns each connection is subscribed to
+type WsSubscriptions struct {
+	mu       sync.RWMutex
+	patterns map[string]map[string]struct{} // connID -> patterns
+}
+
+func NewWsSubscriptions() *WsSubscriptions {
+	return &WsSubscriptions{
+		patterns: make(map[string]map[string]struct{}),
+	}
+}
+
+func (s *WsSubscriptions) Add(connID string, patterns ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, ok := s.patterns[connID]
+	if !ok {
+		set = make(map[string]struct{})
+		s.patterns[connID] = set
+	}
+
+	for _, p := range patterns {
+		set[p] = struct{}{}
+	}
+}
+
+func (s *WsSubscriptions) Remove(connID string, patterns ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, ok := s.patterns[connID]
+	if !ok {
+		return
+	}
+
+	for _, p := range patterns {
+		delete(set, p)
+	}
+
+	if len(set) == 0 {
+		delete(s.patterns, connID)
+	}
+}
+
+// RemoveAll drop every subscription of a connection
+func (s *WsSubscriptions) RemoveAll(connID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.patterns, connID)
+}
+
+// Patterns return the sorted patterns of a connection
+func (s *WsSubscriptions) Patterns(connID string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	patterns := make([]string, 0, len(s.patterns[connID]))
+	for p := range s.patterns[connID] {
+		patterns = append(patterns, p)
+	}
+	sort.Strings(patterns)
+
+	return patterns
+}
+
+// Subscribers return the connections with at least one pattern matching the topic
+func (s *WsSubscriptions) Subscribers(topic string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var connIDs []string
+	for connID, set := range s.patterns {
+		for p := range set {
+			if matchTopic(p, topic) {
+				connIDs = append(connIDs, connID)
+				break
+			}
+		}
+	}
+
+	return connIDs
+}
+
+// Subscribed report whether the connection receives the topic
+func (s *WsSubscriptions) Subscribed(connID, topic string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for p := range s.patterns[connID] {
+		if matchTopic(p, topic) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchTopic report whether the topic matches the pattern. Each segment of the pattern may use "*"
+func matchTopic(pattern, topic string) bool {
+	ok, err := path.Match(pattern, topic)
+	return err == nil && ok
+}
+
+// SetAuthorizer set the permission check used at subscribe time
+func (ws *WsConn) SetAuthorizer(authorize AuthorizeFunc) {
+	ws.authorize = authorize
+}
+
+// handleSubscription subscribe or unsubscribe the connection to the topic patterns of a signed message.
+// Every published topic matched by a pattern must be allowed for the user the connection authenticated as
+func (ws *WsConn) handleSubscription(ctx context.Context, connID string, t string, message cryptographer.Message) {
+	meta := message.Metadata
+
+	valid, err := message.Verify()
+	if err != nil || !valid {
+		RecordSignatureFailure("ws")
+		_ = ws.ReplyError(ctx, connID, meta, NewError(Unauthenticated, "ws.badSignature", "invalid envelope signature"))
+		return
+	}
+
+	var req SubscribeReq
+	if err = json.Unmarshal(message.Body, &req); err != nil {
+		_ = ws.ReplyError(ctx, connID, meta, NewError(InvalidRequest, "ws.badSubscription", "cannot decode subscription").WithCause(err))
+		return
+	}
+
+	if t == unsubscribeTopic {
+		ws.subscriptions.Remove(connID, req.Topics...)
+		ws.replySubscription(ctx, connID, meta, nil)
+		return
+	}
+
+	envelopeKey := hex.EncodeToString(message.PublicKey[:])
+	for _, pattern := range req.Topics {
+		if _, err = path.Match(pattern, ""); err != nil {
+			_ = ws.ReplyError(ctx, connID, meta, NewError(InvalidRequest, "ws.badPattern", "invalid topic pattern").
+				WithDetails(map[string]any{"topic": pattern}))
+			return
+		}
+
+		if err = ws.authorizeSubscription(ctx, connID, envelopeKey, pattern); err != nil {
+			_ = ws.ReplyError(ctx, connID, meta, err)
+			return
+		}
+	}
+
+	// Replayed messages must be queued before any newer publish
+	ws.publishMu.Lock()
+	defer ws.publishMu.Unlock()
+
+	ws.subscriptions.Add(connID, req.Topics...)
+
+	var resync []string
+	for topic := range req.Since {
+		if !ws.subscriptions.Subscribed(connID, topic) {
+			delete(req.Since, topic)
+			resync = append(resync, topic)
+		}
+	}
+	resync = append(resync, ws.replaySince(ctx, connID, req.Since)...)
+	sort.Strings(resync)
+
+	ws.replySubscription(ctx, connID, meta, resync)
+}
+
+// authorizeSubscription check the permission of every published topic matched by the pattern.
+// A signed envelope alone is not bound to the connection and could be replayed on another one,
+// so permissioned topics are checked against the key bound by system/authenticate
+func (ws *WsConn) authorizeSubscription(ctx context.Context, connID, envelopeKey, pattern string) error {
+	matched := false
+	for name, topic := range ws.topics {
+		if topic.Handler != nil || !matchTopic(pattern, name) {
+			continue
+		}
+		matched = true
+
+		if topic.Permission == "" {
+			continue
+		}
+
+		publicKey, err := ws.boundKey(connID, envelopeKey)
+		if err != nil {
+			return err
+		}
+
+		if ws.authorize == nil {
+			return NewError(PermissionDenied, "ws.permissionDenied", "permission denied").
+				WithDetails(map[string]any{"topic": name})
+		}
+
+		if err = ws.authorize(ctx, publicKey, topic.Permission); err != nil {
+			return err
+		}
+	}
+
+	if !matched {
+		return NewError(NotFound, "ws.topicNotFound", "topic not found").
+			WithDetails(map[string]any{"topic": pattern})
+	}
+
+	return nil
+}
+
+// boundKey return the key the connection authenticated with. The envelope must be signed by it
+func (ws *WsConn) boundKey(connID, envelopeKey string) (string, error) {
+	publicKey := ws.connectionManager.PublicKey(connID)
+	if publicKey == "" {
+		return "", NewError(Unauthenticated, "ws.notAuthenticated", "authenticate the connection first")
+	}
+
+	if publicKey != envelopeKey {
+		return "", NewError(Unauthenticated, "ws.keyMismatch", "message not signed by the connection key")
+	}
+
+	return publicKey, nil
+}
+
+// authorizeMessage check a message sent to a permissioned topic: a valid envelope signed by the
+// connection key, whose owner holds the permission
+func (ws *WsConn) authorizeMessage(ctx context.Context, connID string, message cryptographer.Message, permission string) error {
+	valid, err := message.Verify()
+	if err != nil || !valid {
+		RecordSignatureFailure("ws")
+		return NewError(Unauthenticated, "ws.badSignature", "invalid envelope signature")
+	}
+
+	publicKey, err := ws.boundKey(connID, hex.EncodeToString(message.PublicKey[:]))
+	if err != nil {
+		return err
+	}
+
+	if ws.authorize == nil {
+		return NewError(PermissionDenied, "ws.permissionDenied", "permission denied")
+	}
+
+	return ws.authorize(ctx, publicKey, permission)
+}
+
+func (ws *WsConn) replySubscription(ctx context.Context, connID string, meta cryptographer.Metadata, resync []string) {
+	msg, err := cryptographer.Encode(ws.signer, cryptographer.Metadata{
+		Domain:        meta.Domain,
+		Action:        meta.Action,
+		CorrelationID: meta.CorrelationID,
+	}, SubscribeResp{
+		Topics: ws.subscriptions.Patterns(connID),
+		Resync: resync,
+		Code:   OK,
+	})
+	if err != nil {
+		ws.log.ErrorContext(ctx, "cannot encode subscription reply", "err", err)
+		return
+	}
+
+	if err = ws.SendTo(ctx, connID, *msg); err != nil {
+		ws.log.ErrorContext(ctx, "cannot send subscription reply", "err", err)
+	}
+}
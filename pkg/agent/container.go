@@ -1,6 +1,7 @@
 package agent
 
 type Container struct {
+	ID      string
 	Name    string
 	Image   string
 	Network Network
@@ -9,4 +10,7 @@ type Container struct {
 	Volumes []Volume
 	EnvVars []EnvVar
 	Labels  []Label
+	State   string // created, running, exited...
+	Status  string // Human readable, e.g. "Up 2 hours"
+	Created int64  // Unix seconds
 }
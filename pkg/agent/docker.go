@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
 	"orbital/pkg/logger"
 	"strings"
+	"time"
 )
 
 type Docker struct {
@@ -112,10 +114,14 @@ func (agent *Docker) ListContainers() ([]*Container, error) {
 		}
 
 		orbitalContainers = append(orbitalContainers, &Container{
-			Name:   c.Names[0],
-			Image:  c.Image,
-			Ports:  ports,
-			Labels: labels,
+			ID:      c.ID,
+			Name:    strings.TrimPrefix(c.Names[0], "/"),
+			Image:   c.Image,
+			Ports:   ports,
+			Labels:  labels,
+			State:   c.State,
+			Status:  c.Status,
+			Created: c.Created,
 		})
 	}
 
@@ -209,9 +215,48 @@ func (agent *Docker) ensureNetworkExist(net Network) error {
 	return nil
 }
 
-func NewDocker() (*Docker, error) {
+// WatchContainers call fn for every lifecycle event of the managed containers.
+// Blocks until the context ends or the event stream fails
+func (agent *Docker) WatchContainers(ctx context.Context, fn func(ContainerEvent)) error {
+	filterArgs := filters.NewArgs()
+	filterArgs.Add("type", string(events.ContainerEventType))
+	filterArgs.Add("label", "orbital.managed=true")
+
+	msgs, errs := agent.client.Events(ctx, events.ListOptions{Filters: filterArgs})
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errs:
+			return err
+		case msg := <-msgs:
+			fn(ContainerEvent{
+				ID:     msg.Actor.ID,
+				Name:   msg.Actor.Attributes["name"],
+				Image:  msg.Actor.Attributes["image"],
+				Action: string(msg.Action),
+				Time:   time.Unix(0, msg.TimeNano),
+			})
+		}
+	}
+}
+
+// Ping check the docker daemon answers
+func (agent *Docker) Ping(ctx context.Context) error {
+	_, err := agent.client.Ping(ctx)
+	return err
+}
+
+// NewDocker connect to the daemon at host, or the one set by DOCKER_HOST when empty
+func NewDocker(host string) (*Docker, error) {
 	lg := logger.New(logger.LevelDebug, logger.FormatString)
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+
+	opts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
+	if host != "" {
+		opts = append(opts, client.WithHost(host))
+	}
+
+	cli, err := client.NewClientWithOpts(opts...)
 	if err != nil {
 		return nil, err
 	}
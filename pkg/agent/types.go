@@ -1,5 +1,7 @@
 package agent
 
+import "time"
+
 type Network struct {
 	Name string
 }
@@ -30,3 +32,12 @@ type Label struct {
 	Name  string
 	Value string
 }
+
+// ContainerEvent lifecycle change of a managed container: create, start, die, stop, destroy, health_status...
+type ContainerEvent struct {
+	ID     string
+	Name   string
+	Image  string
+	Action string
+	Time   time.Time
+}
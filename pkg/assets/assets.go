@@ -0,0 +1,201 @@
+package assets
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// STATIC ASSETS of the web UI, prepared at build time
+// - Fingerprint - copy entry files to content hashed names, listed in the manifest
+// - Precompress - write the .gz and .br variants next to the compressible files
+// - LoadManifest - read the manifest from the embedded files
+
+// ManifestFile name of the manifest, at the root of the assets directory
+const ManifestFile = "manifest.json"
+
+// Variant suffixes of the precompressed files
+const (
+	GzipExt   = ".gz"
+	BrotliExt = ".br"
+)
+
+// minCompressSize files smaller are not worth a compressed variant
+const minCompressSize = 1024
+
+// compressible extensions. Images and fonts like woff2 are already compressed
+var compressible = map[string]bool{
+	".html": true, ".css": true, ".js": true, ".wasm": true, ".json": true,
+	".svg": true, ".txt": true, ".map": true, ".ttf": true, ".eot": true,
+}
+
+// Manifest logical asset name to its content hashed name, e.g. orbital.wasm: orbital.3f2a9c01de.wasm
+type Manifest map[string]string
+
+// Hashed report whether name is the content hashed name of an asset
+func (m Manifest) Hashed(name string) bool {
+	for _, hashed := range m {
+		if hashed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// HashedName name with the first 10 hex chars of the content SHA-256 before the extension
+func HashedName(name string, content []byte) string {
+	sum := sha256.Sum256(content)
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(sum[:5]) + ext
+}
+
+// Fingerprint copy the entries of dir to their hashed names and write the manifest.
+// Copies made by a previous run are removed. Missing entries are skipped
+func Fingerprint(dir string, entries []string) (Manifest, error) {
+	if old, err := LoadManifest(os.DirFS(dir)); err == nil {
+		for _, hashed := range old {
+			_ = os.Remove(filepath.Join(dir, hashed))
+		}
+	}
+
+	manifest := Manifest{}
+	for _, name := range entries {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w:[%v]", ErrAssetRead, err)
+		}
+
+		hashed := HashedName(name, content)
+		if err = os.WriteFile(filepath.Join(dir, hashed), content, 0644); err != nil {
+			return nil, fmt.Errorf("%w:[%v]", ErrAssetWrite, err)
+		}
+		manifest[name] = hashed
+	}
+
+	raw, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("%w:[%v]", ErrManifestWrite, err)
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, ManifestFile), raw, 0644); err != nil {
+		return nil, fmt.Errorf("%w:[%v]", ErrManifestWrite, err)
+	}
+
+	return manifest, nil
+}
+
+// Precompress write the gzip and brotli variants of the compressible files of dir, except the excluded paths.
+// Stale variants are removed first and a variant is only kept when smaller. Returns the number of written files
+func Precompress(dir string, exclude ...string) (int, error) {
+	written := 0
+	skip := map[string]bool{}
+	for _, p := range exclude {
+		skip[filepath.Join(dir, p)] = true
+	}
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		if ext := filepath.Ext(p); ext == GzipExt || ext == BrotliExt {
+			return os.Remove(p)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%w:[%v]", ErrAssetWrite, err)
+	}
+
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || skip[p] || !compressible[filepath.Ext(p)] {
+			return nil
+		}
+
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("%w:[%v]", ErrAssetRead, err)
+		}
+		if len(content) < minCompressSize {
+			return nil
+		}
+
+		gz, br, err := Compress(content)
+		if err != nil {
+			return err
+		}
+
+		for ext, variant := range map[string][]byte{GzipExt: gz, BrotliExt: br} {
+			if len(variant) >= len(content) {
+				continue
+			}
+			if err = os.WriteFile(p+ext, variant, 0644); err != nil {
+				return fmt.Errorf("%w:[%v]", ErrAssetWrite, err)
+			}
+			written++
+		}
+
+		return nil
+	})
+
+	return written, err
+}
+
+// Compress content with gzip and brotli, both at best compression
+func Compress(content []byte) ([]byte, []byte, error) {
+	var gz bytes.Buffer
+	gw, _ := gzip.NewWriterLevel(&gz, gzip.BestCompression)
+	if _, err := gw.Write(content); err != nil {
+		return nil, nil, fmt.Errorf("%w:[%v]", ErrCompress, err)
+	}
+	if err := gw.Close(); err != nil {
+		return nil, nil, fmt.Errorf("%w:[%v]", ErrCompress, err)
+	}
+
+	var br bytes.Buffer
+	bw := brotli.NewWriterLevel(&br, brotli.BestCompression)
+	if _, err := bw.Write(content); err != nil {
+		return nil, nil, fmt.Errorf("%w:[%v]", ErrCompress, err)
+	}
+	if err := bw.Close(); err != nil {
+		return nil, nil, fmt.Errorf("%w:[%v]", ErrCompress, err)
+	}
+
+	return gz.Bytes(), br.Bytes(), nil
+}
+
+// LoadManifest read the manifest at the root of fsys
+func LoadManifest(fsys fs.FS) (Manifest, error) {
+	raw, err := fs.ReadFile(fsys, ManifestFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w:[%v]", ErrManifestRead, err)
+	}
+
+	var manifest Manifest
+	if err = json.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("%w:[%v]", ErrManifestRead, err)
+	}
+
+	return manifest, nil
+}
@@ -0,0 +1,11 @@
+package assets
+
+import "errors"
+
+var (
+	ErrManifestRead  = errors.New("cannot read asset manifest")
+	ErrManifestWrite = errors.New("cannot write asset manifest")
+	ErrAssetRead     = errors.New("cannot read asset")
+	ErrAssetWrite    = errors.New("cannot write asset")
+	ErrCompress      = errors.New("cannot compress asset")
+)
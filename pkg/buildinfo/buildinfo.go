@@ -0,0 +1,38 @@
+package buildinfo
+
+import (
+	"runtime"
+	"time"
+)
+
+// started process start, used for the uptime
+var started = time.Now()
+
+// Info build metadata injected at link time. See the Taskfile ldflags
+type Info struct {
+	Version   string    `json:"version"`
+	Compile   string    `json:"compile"`
+	Branch    string    `json:"branch"`
+	GoVersion string    `json:"goVersion"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// New fill the Go version and process start. An empty version reports "dev"
+func New(version, compile, branch string) Info {
+	if version == "" {
+		version = "dev"
+	}
+
+	return Info{
+		Version:   version,
+		Compile:   compile,
+		Branch:    branch,
+		GoVersion: runtime.Version(),
+		StartedAt: started,
+	}
+}
+
+// Uptime since the process started
+func Uptime() time.Duration {
+	return time.Since(started)
+}
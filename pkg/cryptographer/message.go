@@ -131,6 +131,33 @@ func (m *Message) Sign(secretKeySeed []byte) error {
 	return nil
 }
 
+// SignWith sign the message using a Signer
+func (m *Message) SignWith(signer Signer) error {
+	serial, err := m.Serialize()
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256(serial)
+	sig, err := signer.Sign(hash[:])
+	if err != nil {
+		return fmt.Errorf("%w:[%v]", ErrSignMessage, err)
+	}
+
+	if len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("%w:[signature size: %d]", ErrSignMessage, len(sig))
+	}
+
+	m.ID, err = m.ComputeID()
+	if err != nil {
+		return err
+	}
+
+	m.Signature = [64]byte(sig)
+
+	return nil
+}
+
 func (m *Message) Verify() (bool, error) {
 
 	serial, err := m.Serialize()
@@ -143,11 +170,16 @@ func (m *Message) Verify() (bool, error) {
 	return ed25519.Verify(m.PublicKey[:], hash[:], m.Signature[:]), nil
 }
 
-func Encode(sk PrivateKey, metadata Metadata, body any) (*Message, error) {
+// Encode build and sign a message. PrivateKey can be passed directly as Signer
+func Encode(signer Signer, metadata Metadata, body any) (*Message, error) {
 	var (
 		b []byte
 	)
 
+	if signer == nil {
+		return nil, ErrSignMessage
+	}
+
 	if body != nil {
 		b, _ = json.Marshal(body)
 	}
@@ -159,11 +191,11 @@ func Encode(sk PrivateKey, metadata Metadata, body any) (*Message, error) {
 		Body:      b,
 	}
 
-	if err := msg.SetPublicKey(sk.PublicKey().Bytes()); err != nil {
+	if err := msg.SetPublicKey(signer.PublicKey().Bytes()); err != nil {
 		return nil, ErrPubKeyMessage
 	}
 
-	if err := msg.Sign(sk.Seed()); err != nil {
+	if err := msg.SignWith(signer); err != nil {
 		return nil, ErrSignMessage
 	}
 
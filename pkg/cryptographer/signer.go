@@ -0,0 +1,22 @@
+package cryptographer
+
+import (
+	"crypto/ed25519"
+	"fmt"
+)
+
+// Signer sign message digests on behalf of a key.
+// The key material does not need to be in memory (keystore, HSM, agent)
+type Signer interface {
+	PublicKey() PublicKey
+	Sign(digest []byte) ([]byte, error)
+}
+
+// Sign the digest with the private key. PrivateKey implements Signer
+func (sk PrivateKey) Sign(digest []byte) ([]byte, error) {
+	if len(sk.key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w:[%d]", ErrInvalidKeySize, len(sk.key))
+	}
+
+	return ed25519.Sign(sk.key, digest), nil
+}
@@ -1,9 +1,14 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/sqlite"
@@ -22,11 +27,17 @@ func (db *DB) Client() *sql.DB {
 
 func NewDB(dbDirPath string) (*DB, error) {
 	dbpath := filepath.Join(dbDirPath, "orbital.db")
-	db, err := sql.Open("sqlite", dbpath)
+	sqliteDB, err := sql.Open("sqlite", dbpath)
 	if err != nil {
 		return nil, fmt.Errorf("%w:[%s]", ErrDBOpen, err.Error())
 	}
 
+	// Reopen through the timed connector to feed the query duration metric.
+	// Readers wait for the background writers (audit, replay log) instead of failing with SQLITE_BUSY
+	dsn := dbpath + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db := sql.OpenDB(timedConnector{dsn: dsn, driver: sqliteDB.Driver()})
+	_ = sqliteDB.Close()
+
 	if err = db.Ping(); err != nil {
 		return nil, fmt.Errorf("%w:[%s]", ErrDBConnect, err.Error())
 	}
@@ -56,3 +67,97 @@ func AutoMigrate(db *DB, orbitalDir string) error {
 
 	return nil
 }
+
+// Backup write a consistent copy of the database to dst, replacing it. With the WAL journal the main file
+// alone misses the committed pages not checkpointed yet, VACUUM INTO reads through the log
+func (db *DB) Backup(ctx context.Context, dst string) error {
+	if err := os.Remove(dst); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w:[%s]", ErrBackup, err.Error())
+	}
+
+	if _, err := db.client.ExecContext(ctx, "VACUUM INTO ?", dst); err != nil {
+		return fmt.Errorf("%w:[%s]", ErrBackup, err.Error())
+	}
+
+	return nil
+}
+
+// Ping check the database answers
+func (db *DB) Ping(ctx context.Context) error {
+	if err := db.client.PingContext(ctx); err != nil {
+		return fmt.Errorf("%w:[%s]", ErrDBConnect, err.Error())
+	}
+
+	return nil
+}
+
+// MigrationVersion return the applied schema version and whether the last migration failed
+func (db *DB) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+
+	err := db.client.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("%w:[%s]", ErrMigrationRead, err.Error())
+	}
+
+	return uint(version), dirty, nil
+}
+
+// CheckMigrations fail when the schema is dirty or older than the latest migration shipped in fsys
+func (db *DB) CheckMigrations(ctx context.Context, fsys fs.FS, dir string) error {
+	latest, err := LatestMigration(fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := db.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return fmt.Errorf("%w:[version: %d]", ErrMigrationDirty, version)
+	}
+
+	if version < latest {
+		return fmt.Errorf("%w:[version: %d, latest: %d]", ErrMigrationBehind, version, latest)
+	}
+
+	return nil
+}
+
+// LatestMigration return the highest version of the <version>_<name>.up.sql files in dir
+func LatestMigration(fsys fs.FS, dir string) (uint, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return 0, fmt.Errorf("%w:[%s]", ErrMigrationRead, err.Error())
+	}
+
+	var latest uint
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".up.sql") {
+			continue
+		}
+
+		prefix, _, found := strings.Cut(name, "_")
+		if !found {
+			continue
+		}
+
+		v, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		latest = max(latest, uint(v))
+	}
+
+	return latest, nil
+}
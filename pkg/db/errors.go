@@ -3,6 +3,10 @@ package db
 import "errors"
 
 var (
-	ErrDBOpen    = errors.New("failed to open database")
-	ErrDBConnect = errors.New("cannot connect to database")
+	ErrDBOpen          = errors.New("failed to open database")
+	ErrDBConnect       = errors.New("cannot connect to database")
+	ErrMigrationRead   = errors.New("cannot read migrations")
+	ErrMigrationDirty  = errors.New("last migration failed, database is dirty")
+	ErrMigrationBehind = errors.New("database schema is behind the binary migrations")
+	ErrBackup          = errors.New("cannot backup database")
 )
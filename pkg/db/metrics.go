@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"orbital/pkg/metrics"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("orbital/db")
+
+var queryDuration = metrics.NewHistogram("orbital_sqlite_query_duration_seconds",
+	"SQLite statement duration by operation: query or exec", nil, "op")
+
+// timedConnector open connections of the wrapped driver, time and trace their statements
+type timedConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c timedConnector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &timedConn{Conn: conn}, nil
+}
+
+func (c timedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// timedConn forward to the driver connection. Optional interfaces missing on it return driver.ErrSkip
+// so database/sql falls back to prepared statements
+type timedConn struct {
+	driver.Conn
+}
+
+func (c *timedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, end := observe(ctx, "query", query)
+	defer end()
+	return q.QueryContext(ctx, query, args)
+}
+
+func (c *timedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, end := observe(ctx, "exec", query)
+	defer end()
+	return e.ExecContext(ctx, query, args)
+}
+
+// observe time the statement and trace it as a child of the request span
+func observe(ctx context.Context, op, query string) (context.Context, func()) {
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, "sqlite."+op, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "sqlite"),
+			attribute.String("db.statement", query),
+		))
+
+	return ctx, func() {
+		queryDuration.Since(start, op)
+		span.End()
+	}
+}
+
+func (c *timedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, query)
+	}
+
+	return c.Conn.Prepare(query)
+}
+
+func (c *timedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+
+	return c.Conn.Begin()
+}
+
+func (c *timedConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c *timedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+func (c *timedConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+
+	return true
+}
+
+func (c *timedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if ch, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return ch.CheckNamedValue(nv)
+	}
+
+	return driver.ErrSkip
+}
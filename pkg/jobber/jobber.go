@@ -2,6 +2,7 @@ package jobber
 
 import (
 	"fmt"
+	"orbital/pkg/metrics"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -31,6 +32,15 @@ type job struct {
 
 var jobCounter uint64
 
+var (
+	jobRuns = metrics.NewCounter("orbital_jobber_runs_total",
+		"Job runs by job id", "job")
+	jobErrors = metrics.NewCounter("orbital_jobber_errors_total",
+		"Job runs that panicked, by job id", "job")
+	jobDuration = metrics.NewHistogram("orbital_jobber_run_duration_seconds",
+		"Job run duration by job id", nil, "job")
+)
+
 func New(workerCount int) *Runner {
 	return &Runner{
 		jobs:  make(map[string]*job),
@@ -94,7 +104,7 @@ func (r *Runner) runJob(j *job) {
 		case <-ticker.C:
 			select {
 			case r.pool <- struct{}{}:
-				j.task()
+				j.run()
 				j.runCount++
 
 				if j.maxRuns > 0 && j.runCount >= j.maxRuns {
@@ -113,3 +123,17 @@ func (r *Runner) runJob(j *job) {
 		}
 	}
 }
+
+// run execute the task once. A panic is counted as an error and does not stop the job
+func (j *job) run() {
+	start := time.Now()
+	defer func() {
+		if rec := recover(); rec != nil {
+			jobErrors.Inc(j.id)
+		}
+		jobRuns.Inc(j.id)
+		jobDuration.Since(start, j.id)
+	}()
+
+	j.task()
+}
@@ -0,0 +1,236 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft JSON Schema dialect produced by the Reflector
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema subset of JSON Schema needed to describe Go structs
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// Reflector build schemas from Go values.
+// Named structs are stored once as definitions and referenced with RefPrefix
+type Reflector struct {
+	RefPrefix string
+	defs      map[string]*Schema
+	names     map[reflect.Type]string
+}
+
+// NewReflector create a reflector. Use "#/$defs/" for standalone schemas
+// or "#/components/schemas/" for OpenAPI documents
+func NewReflector(refPrefix string) *Reflector {
+	return &Reflector{
+		RefPrefix: refPrefix,
+		defs:      make(map[string]*Schema),
+		names:     make(map[reflect.Type]string),
+	}
+}
+
+// Definitions collected so far, keyed by definition name
+func (r *Reflector) Definitions() map[string]*Schema {
+	return r.defs
+}
+
+// Reflect return the schema for the value type. Nil values produce nil
+func (r *Reflector) Reflect(v any) *Schema {
+	if v == nil {
+		return nil
+	}
+
+	return r.reflectType(reflect.TypeOf(v))
+}
+
+// Reflect a standalone schema embedding all the definitions it needs
+func Reflect(v any) *Schema {
+	if v == nil {
+		return nil
+	}
+
+	r := NewReflector("#/$defs/")
+	s := r.Reflect(v)
+
+	// Keep the root inline so the schema is readable without resolving the first ref
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, r.RefPrefix)
+		root := *r.defs[name]
+		s = &root
+	}
+
+	s.Schema = Draft
+	if len(r.defs) > 0 {
+		s.Defs = r.defs
+	}
+
+	return s
+}
+
+func (r *Reflector) reflectType(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return r.reflectType(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		// encoding/json writes byte slices as base64 strings
+		if t.Elem().Kind() == reflect.Uint8 {
+			if t.Kind() == reflect.Slice {
+				return &Schema{Type: "string", Format: "byte"}
+			}
+		}
+		return &Schema{Type: "array", Items: r.reflectType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.reflectType(t.Elem())}
+	case reflect.Struct:
+		return r.reflectStruct(t)
+	default:
+		// interfaces, funcs, channels: anything goes
+		return &Schema{}
+	}
+}
+
+func (r *Reflector) reflectStruct(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return r.structSchema(t)
+	}
+
+	if name, found := r.names[t]; found {
+		return &Schema{Ref: r.RefPrefix + name}
+	}
+
+	name := r.defName(t)
+	r.names[t] = name
+
+	// Reserve the slot first to allow recursive types
+	r.defs[name] = &Schema{}
+	*r.defs[name] = *r.structSchema(t)
+
+	return &Schema{Ref: r.RefPrefix + name}
+}
+
+func (r *Reflector) structSchema(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, omitEmpty, skip := jsonName(f)
+		if skip {
+			continue
+		}
+
+		// Embedded structs without a tag are flattened by encoding/json
+		if f.Anonymous && f.Tag.Get("json") == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded := r.structSchema(ft)
+				for k, v := range embedded.Properties {
+					s.Properties[k] = v
+				}
+				s.Required = append(s.Required, embedded.Required...)
+				continue
+			}
+		}
+
+		fs := r.reflectType(f.Type)
+		if desc := f.Tag.Get("description"); desc != "" {
+			fs = withDescription(fs, desc)
+		}
+
+		s.Properties[name] = fs
+		if !omitEmpty && f.Type.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	return s
+}
+
+// defName prefix the type name with its package to avoid collisions (apps.App)
+func (r *Reflector) defName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+
+	name := t.Name()
+	if pkg != "" {
+		name = pkg + "." + name
+	}
+
+	// Generic instantiations carry the full type parameters in the name
+	name = strings.NewReplacer("[", "_", "]", "", "/", "_", "*", "").Replace(name)
+
+	return name
+}
+
+func jsonName(f reflect.StructField) (name string, omitEmpty, skip bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = f.Name
+	}
+
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" || opt == "omitzero" {
+			omitEmpty = true
+		}
+	}
+
+	return name, omitEmpty, false
+}
+
+func withDescription(s *Schema, desc string) *Schema {
+	// $ref siblings are allowed since 2020-12, keep a copy to not alter shared refs
+	cp := *s
+	cp.Description = desc
+	return &cp
+}
@@ -1,9 +1,11 @@
 package logger
 
 import (
+	"context"
 	"io"
 	"log/slog"
 	"os"
+	"sync/atomic"
 )
 
 type Level int
@@ -24,26 +26,96 @@ const (
 )
 
 type Logger struct {
-	handler *slog.Logger
+	handler atomic.Pointer[slog.Logger] // Swapped by Configure
 }
 
 func (l *Logger) Debug(msg string, args ...any) {
-	l.handler.Debug(msg, args...)
+	l.handler.Load().Debug(msg, args...)
 }
 
 func (l *Logger) Info(msg string, args ...any) {
-	l.handler.Info(msg, args...)
+	l.handler.Load().Info(msg, args...)
 }
 
 func (l *Logger) Warn(msg string, args ...any) {
-	l.handler.Warn(msg, args...)
+	l.handler.Load().Warn(msg, args...)
 }
 
 func (l *Logger) Error(msg string, args ...any) {
-	l.handler.Error(msg, args...)
+	l.handler.Load().Error(msg, args...)
+}
+
+// DebugContext log with the attributes carried by ctx. See WithAttrs
+func (l *Logger) DebugContext(ctx context.Context, msg string, args ...any) {
+	l.handler.Load().DebugContext(ctx, msg, args...)
+}
+
+func (l *Logger) InfoContext(ctx context.Context, msg string, args ...any) {
+	l.handler.Load().InfoContext(ctx, msg, args...)
+}
+
+func (l *Logger) WarnContext(ctx context.Context, msg string, args ...any) {
+	l.handler.Load().WarnContext(ctx, msg, args...)
+}
+
+func (l *Logger) ErrorContext(ctx context.Context, msg string, args ...any) {
+	l.handler.Load().ErrorContext(ctx, msg, args...)
+}
+
+type ctxAttrsKey struct{}
+
+// WithAttrs return a context whose attributes are appended to the records logged with it.
+// Args are key value pairs, as for Info
+func WithAttrs(ctx context.Context, args ...any) context.Context {
+	r := slog.Record{}
+	r.Add(args...)
+
+	attrs, _ := ctx.Value(ctxAttrsKey{}).([]slog.Attr)
+	attrs = append(attrs[:len(attrs):len(attrs)], recordAttrs(r)...)
+
+	return context.WithValue(ctx, ctxAttrsKey{}, attrs)
+}
+
+func recordAttrs(r slog.Record) []slog.Attr {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+
+	return attrs
+}
+
+// contextHandler add the WithAttrs attributes of the record context
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs, ok := ctx.Value(ctxAttrsKey{}).([]slog.Attr); ok {
+		r.AddAttrs(attrs...)
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
 }
 
 func New(lvl Level, fmt Format) *Logger {
+	l := &Logger{}
+	l.Configure(lvl, fmt)
+
+	return l
+}
+
+// Configure change the level and format. Safe while other goroutines log
+func (l *Logger) Configure(lvl Level, fmt Format) {
 	opts := parseSlogOpts(slog.Level(lvl))
 
 	var writer io.Writer = os.Stdout
@@ -53,9 +125,7 @@ func New(lvl Level, fmt Format) *Logger {
 		handler = slog.NewJSONHandler(writer, opts)
 	}
 
-	return &Logger{
-		handler: slog.New(handler),
-	}
+	l.handler.Store(slog.New(contextHandler{handler}))
 }
 
 func parseSlogOpts(lvl slog.Level) *slog.HandlerOptions {
@@ -0,0 +1,378 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets latency buckets in seconds
+var DefaultBuckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Default registry. Package level metrics register themselves here
+var Default = NewRegistry()
+
+type collector interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// Registry set of metrics written in the Prometheus text exposition format
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, found := r.collectors[c.name()]; found {
+		panic(fmt.Sprintf("metrics: %s already registered", c.name()))
+	}
+
+	r.collectors[c.name()] = c
+}
+
+// WriteText write every metric, sorted by name
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.collectors))
+	for n := range r.collectors {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	collectors := make([]collector, 0, len(names))
+	for _, n := range names {
+		collectors = append(collectors, r.collectors[n])
+	}
+	r.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(bw)
+	}
+
+	return bw.Flush()
+}
+
+// family common part of the vectors: name, help and the series indexed by label values
+type family struct {
+	mu     sync.Mutex
+	fqName string
+	help   string
+	kind   string
+	labels []string
+}
+
+func (f *family) name() string {
+	return f.fqName
+}
+
+func (f *family) header(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", f.fqName, escapeHelp(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.fqName, f.kind)
+}
+
+func (f *family) key(values []string) string {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.fqName, len(f.labels), len(values)))
+	}
+
+	return strings.Join(values, "\xff")
+}
+
+// CounterVec monotonic counters partitioned by labels
+type CounterVec struct {
+	family
+	series map[string]*series
+}
+
+type series struct {
+	values []string
+	value  float64
+}
+
+// NewCounter register a counter vector in the default registry
+func NewCounter(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{
+		family: family{fqName: name, help: help, kind: "counter", labels: labels},
+		series: make(map[string]*series),
+	}
+	Default.register(c)
+
+	return c
+}
+
+// Inc add one to the series
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add a positive delta to the series
+func (c *CounterVec) Add(delta float64, values ...string) {
+	if delta < 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	k := c.key(values)
+	s, found := c.series[k]
+	if !found {
+		s = &series{values: values}
+		c.series[k] = s
+	}
+	s.value += delta
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.header(w)
+	for _, k := range sortedKeys(c.series) {
+		s := c.series[k]
+		writeSample(w, c.fqName, c.labels, s.values, "", "", s.value)
+	}
+}
+
+// GaugeVec values that go up and down, partitioned by labels
+type GaugeVec struct {
+	family
+	series map[string]*series
+}
+
+// NewGauge register a gauge vector in the default registry
+func NewGauge(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{
+		family: family{fqName: name, help: help, kind: "gauge", labels: labels},
+		series: make(map[string]*series),
+	}
+	Default.register(g)
+
+	return g
+}
+
+func (g *GaugeVec) Set(value float64, values ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	k := g.key(values)
+	s, found := g.series[k]
+	if !found {
+		s = &series{values: values}
+		g.series[k] = s
+	}
+	s.value = value
+}
+
+func (g *GaugeVec) Add(delta float64, values ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	k := g.key(values)
+	s, found := g.series[k]
+	if !found {
+		s = &series{values: values}
+		g.series[k] = s
+	}
+	s.value += delta
+}
+
+func (g *GaugeVec) Inc(values ...string) {
+	g.Add(1, values...)
+}
+
+func (g *GaugeVec) Dec(values ...string) {
+	g.Add(-1, values...)
+}
+
+func (g *GaugeVec) write(w *bufio.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.header(w)
+	for _, k := range sortedKeys(g.series) {
+		s := g.series[k]
+		writeSample(w, g.fqName, g.labels, s.values, "", "", s.value)
+	}
+}
+
+// EmitFunc report one sample of a GaugeFunc
+type EmitFunc func(value float64, values ...string)
+
+// GaugeFunc gauges read at scrape time
+type GaugeFunc struct {
+	family
+	collect func(emit EmitFunc)
+}
+
+// NewGaugeFunc register a gauge whose samples are produced by collect on every scrape
+func NewGaugeFunc(name, help string, collect func(emit EmitFunc), labels ...string) *GaugeFunc {
+	g := &GaugeFunc{
+		family:  family{fqName: name, help: help, kind: "gauge", labels: labels},
+		collect: collect,
+	}
+	Default.register(g)
+
+	return g
+}
+
+func (g *GaugeFunc) write(w *bufio.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.header(w)
+	g.collect(func(value float64, values ...string) {
+		g.key(values)
+		writeSample(w, g.fqName, g.labels, values, "", "", value)
+	})
+}
+
+// HistogramVec observations counted in cumulative buckets, partitioned by labels
+type HistogramVec struct {
+	family
+	buckets []float64
+	series  map[string]*histogram
+}
+
+type histogram struct {
+	values []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram register a histogram vector in the default registry. Nil buckets use DefaultBuckets
+func NewHistogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+
+	h := &HistogramVec{
+		family:  family{fqName: name, help: help, kind: "histogram", labels: labels},
+		buckets: b,
+		series:  make(map[string]*histogram),
+	}
+	Default.register(h)
+
+	return h
+}
+
+func (h *HistogramVec) Observe(value float64, values ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	k := h.key(values)
+	s, found := h.series[k]
+	if !found {
+		s = &histogram{values: values, counts: make([]uint64, len(h.buckets))}
+		h.series[k] = s
+	}
+
+	for i, upper := range h.buckets {
+		if value <= upper {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += value
+}
+
+// Since observe the seconds elapsed from start
+func (h *HistogramVec) Since(start time.Time, values ...string) {
+	h.Observe(time.Since(start).Seconds(), values...)
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.header(w)
+	for _, k := range sortedKeys(h.series) {
+		s := h.series[k]
+		for i, upper := range h.buckets {
+			writeSample(w, h.fqName+"_bucket", h.labels, s.values, "le", formatFloat(upper), float64(s.counts[i]))
+		}
+		writeSample(w, h.fqName+"_bucket", h.labels, s.values, "le", "+Inf", float64(s.count))
+		writeSample(w, h.fqName+"_sum", h.labels, s.values, "", "", s.sum)
+		writeSample(w, h.fqName+"_count", h.labels, s.values, "", "", float64(s.count))
+	}
+}
+
+func writeSample(w *bufio.Writer, name string, labels, values []string, extraLabel, extraValue string, value float64) {
+	w.WriteString(name)
+
+	if len(labels) > 0 || extraLabel != "" {
+		w.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", l, escapeLabel(values[i]))
+		}
+		if extraLabel != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", extraLabel, extraValue)
+		}
+		w.WriteByte('}')
+	}
+
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpReplacer  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelReplacer.Replace(s)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,128 @@
+package ratelimit
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Rate token bucket settings. A zero PerSecond means unlimited
+type Rate struct {
+	PerSecond float64 `yaml:"perSecond" json:"perSecond"`
+	Burst     int     `yaml:"burst" json:"burst"`
+}
+
+// State of one bucket
+type State struct {
+	Key      string    `json:"key"`
+	Tokens   float64   `json:"tokens"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter token buckets indexed by key. Idle full buckets are dropped
+type Limiter struct {
+	mu        sync.Mutex
+	rate      Rate
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func New(rate Rate) *Limiter {
+	if rate.Burst < 1 {
+		rate.Burst = int(math.Ceil(rate.PerSecond))
+	}
+
+	return &Limiter{
+		rate:      rate,
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow take a token for the key. When none is left it returns the wait before the next one
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if l == nil || l.rate.PerSecond <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	b, found := l.buckets[key]
+	if !found {
+		b = &bucket{tokens: float64(l.rate.Burst), last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(l.rate.Burst), b.tokens+now.Sub(b.last).Seconds()*l.rate.PerSecond)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - b.tokens) / l.rate.PerSecond * float64(time.Second))
+	return false, wait
+}
+
+// Rate return the limiter settings
+func (l *Limiter) Rate() Rate {
+	if l == nil {
+		return Rate{}
+	}
+
+	return l.rate
+}
+
+// State return the buckets sorted by key
+func (l *Limiter) State() []State {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	states := make([]State, 0, len(l.buckets))
+	for key, b := range l.buckets {
+		states = append(states, State{
+			Key:      key,
+			Tokens:   math.Min(float64(l.rate.Burst), b.tokens+now.Sub(b.last).Seconds()*l.rate.PerSecond),
+			LastSeen: b.last,
+		})
+	}
+
+	sort.Slice(states, func(i, j int) bool { return states[i].Key < states[j].Key })
+
+	return states
+}
+
+// sweep drop the buckets refilled for long enough to be full again
+func (l *Limiter) sweep(now time.Time) {
+	refill := time.Duration(float64(l.rate.Burst) / l.rate.PerSecond * float64(time.Second))
+	if refill < time.Minute {
+		refill = time.Minute
+	}
+
+	if now.Sub(l.lastSweep) < refill {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > refill {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package rpcgen
+
+import "errors"
+
+var (
+	ErrSchemaRead    = errors.New("cannot read schema")
+	ErrSchemaInvalid = errors.New("invalid schema")
+	ErrGenerate      = errors.New("cannot generate code")
+	ErrWriteOutput   = errors.New("cannot write generated file")
+)
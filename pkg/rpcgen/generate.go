@@ -0,0 +1,304 @@
+package rpcgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const header = "Code generated by orbital gen. DO NOT EDIT."
+
+// File generated output ready to be written
+type File struct {
+	Path    string
+	Content []byte
+}
+
+// Generate render all outputs configured in the schema
+func Generate(s *Schema) ([]File, error) {
+	targets := []struct {
+		path   string
+		tpl    *template.Template
+		isCode bool
+	}{
+		{s.Output.Server, serverTpl, true},
+		{s.Output.Client, clientTpl, true},
+		{s.Output.Docs, docsTpl, false},
+	}
+
+	var files []File
+	for _, target := range targets {
+		if target.path == "" {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := target.tpl.Execute(&buf, s); err != nil {
+			return nil, fmt.Errorf("%w:[%s: %v]", ErrGenerate, target.tpl.Name(), err)
+		}
+
+		content := buf.Bytes()
+		if target.isCode {
+			formatted, err := format.Source(content)
+			if err != nil {
+				return nil, fmt.Errorf("%w:[%s: %v]", ErrGenerate, target.tpl.Name(), err)
+			}
+			content = formatted
+		}
+
+		files = append(files, File{
+			Path:    s.resolve(target.path),
+			Content: content,
+		})
+	}
+
+	return files, nil
+}
+
+// Write generated files to disk, creating parent dirs if needed
+func Write(files []File) error {
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+			return fmt.Errorf("%w:[%s: %v]", ErrWriteOutput, f.Path, err)
+		}
+
+		if err := os.WriteFile(f.Path, f.Content, 0644); err != nil {
+			return fmt.Errorf("%w:[%s: %v]", ErrWriteOutput, f.Path, err)
+		}
+	}
+
+	return nil
+}
+
+var funcs = template.FuncMap{
+	"header":  func() string { return header },
+	"comment": comment,
+	"lower":   lowerFirst,
+	"anchor":  strings.ToLower,
+	"tag": func(f Field) string {
+		if f.OmitEmpty {
+			return fmt.Sprintf("`json:\"%s,omitempty\"`", f.JSON)
+		}
+		return fmt.Sprintf("`json:\"%s\"`", f.JSON)
+	},
+	"method": func(m string) string {
+		return "http.Method" + strings.ToUpper(m[:1]) + strings.ToLower(m[1:])
+	},
+}
+
+// comment prefix every line of a description with the Go comment marker
+func comment(prefix, text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ""
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight("// "+strings.TrimSpace(l), " ")
+	}
+
+	// Go doc comments start with the identifier. Skip it if the description already does
+	if prefix != "" && !strings.HasPrefix(text, prefix+" ") {
+		lines[0] = "// " + prefix + " " + strings.TrimPrefix(lines[0], "// ")
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+var serverTpl = template.Must(template.New("server").Funcs(funcs).Parse(`// {{ header }}
+// Source: {{ .Service }} schema
+
+package {{ .Package }}
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"orbital/orbital"
+	"orbital/pkg/cryptographer"
+{{- range .Imports }}
+	"{{ . }}"
+{{- end }}
+)
+
+const (
+	Domain = "{{ .Domain }}"
+{{- range .Rpcs }}
+	Action{{ .Name }} = "{{ .Action }}"
+{{- end }}
+)
+
+{{ comment (print .Service) (or .Description "RPC definition") -}}
+type {{ .Service }} interface {
+{{- range .Rpcs }}
+	{{ comment .Name .Description -}}
+	{{ .Name }}(ctx context.Context, req {{ .Request }}) (*{{ .Response }}, error)
+{{- end }}
+}
+{{ range .Types }}
+{{ comment .Name .Description -}}
+type {{ .Name }} struct {
+{{- range .Fields }}
+	{{ .Name }} {{ .Type }} {{ tag . }}{{ if .Description }} // {{ .Description }}{{ end }}
+{{- end }}
+{{- if .Response }}
+	Code  orbital.Code           ` + "`json:\"code\"`" + `
+	Error *orbital.ErrorResponse ` + "`json:\"error,omitempty\"`" + `
+{{- end }}
+}
+{{ end }}
+type {{ lower .Service }}Server struct {
+	server  orbital.HTTPService
+	service {{ .Service }}
+}
+
+func Register{{ .Service }}Server(server orbital.HTTPService, _ orbital.WsService, service {{ .Service }}) {
+	handler := &{{ lower .Service }}Server{
+		server:  server,
+		service: service,
+	}
+
+	group := server.Group("{{ .Service }}"{{ range .Middlewares }},
+		{{ . }}{{ end }},
+	)
+{{ range .Rpcs }}
+	group.Register(orbital.Route{
+		ActionName:  "{{ .Name }}",
+		Handler:     handler.handle{{ .Name }},
+		Method:      {{ method .Method }},
+		Permission:  "{{ .Permission }}",
+		Description: {{ printf "%q" .Description }},
+		Request:     {{ .Request }}{},
+		Response:    {{ .Response }}{},
+	})
+{{ end -}}
+}
+{{ $svc := . }}
+{{- range .Rpcs }}
+func (s *{{ lower $svc.Service }}Server) handle{{ .Name }}(w http.ResponseWriter, r *http.Request) {
+	body, ok := r.Context().Value(cryptographer.BodyCtxKey).([]byte)
+	if !ok {
+		s.server.OnError(w, r, orbital.ErrBadPayload)
+		return
+	}
+
+	var req {{ .Request }}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.server.OnError(w, r, fmt.Errorf("%w:[%v]", orbital.ErrUnmarshalPayload, err))
+			return
+		}
+	}
+
+	res, err := s.service.{{ .Name }}(r.Context(), req)
+	if err != nil {
+		s.server.OnError(w, r, err)
+		return
+	}
+
+	s.server.Reply(w, r, cryptographer.Metadata{
+		Domain: Domain,
+		Action: Action{{ .Name }},
+	}, res)
+}
+{{ end -}}
+`))
+
+var clientTpl = template.Must(template.New("client").Funcs(funcs).Parse(`// {{ header }}
+// Source: {{ .Service }} schema
+
+package {{ .Output.ClientPackage }}
+
+import (
+	"orbital/pkg/cryptographer"
+	"orbital/web/wasm/pkg/transport"
+)
+{{ range .Types }}
+{{ comment .Name .Description -}}
+type {{ .Name }} struct {
+{{- range .Fields }}
+	{{ .Name }} {{ .Type }} {{ tag . }}{{ if .Description }} // {{ .Description }}{{ end }}
+{{- end }}
+{{- if .Response }}
+	Code  transport.Code           ` + "`json:\"code\"`" + `
+	Error *transport.ErrorResponse ` + "`json:\"error,omitempty\"`" + `
+{{- end }}
+}
+{{ end }}
+{{ comment (print .Service "Client") (print "typed client for " .Service) -}}
+type {{ .Service }}Client struct {
+	signer transport.SignerFunc
+}
+
+func New{{ .Service }}Client(signer transport.SignerFunc) *{{ .Service }}Client {
+	return &{{ .Service }}Client{
+		signer: signer,
+	}
+}
+{{ $svc := . }}
+{{- range .Rpcs }}
+{{ comment .Name .Description -}}
+func (c *{{ $svc.Service }}Client) {{ .Name }}(req {{ .Request }}) (*{{ .Response }}, error) {
+	var res {{ .Response }}
+	err := transport.Call("rpc/{{ $svc.Service }}/{{ .Name }}", c.signer, cryptographer.Metadata{
+		Domain: "{{ $svc.Domain }}",
+		Action: "{{ .Action }}",
+	}, req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+{{ end -}}
+`))
+
+var docsTpl = template.Must(template.New("docs").Funcs(funcs).Parse(`<!-- {{ header }} -->
+
+# {{ .Service }}
+
+{{ if .Description }}{{ .Description }}
+
+{{ end -}}
+| RPC | Path | Method | Domain/Action | Request | Response |
+|-----|------|--------|---------------|---------|----------|
+{{- $svc := . }}
+{{- range .Rpcs }}
+| {{ .Name }} | ` + "`/rpc/{{ $svc.Service }}/{{ .Name }}`" + ` | {{ .Method }} | ` + "`{{ $svc.Domain }}/{{ .Action }}`" + ` | [{{ .Request }}](#{{ anchor .Request }}) | [{{ .Response }}](#{{ anchor .Response }}) |
+{{- end }}
+
+Requests are sent as signed envelopes. The body of the envelope is the JSON request.
+Responses are signed by the node. Failed calls return an ` + "`ErrorReply`" + ` with a non-zero code.
+{{ range .Rpcs }}
+## {{ .Name }}
+{{ if .Description }}
+{{ .Description }}
+{{ end }}
+- Path: ` + "`/rpc/{{ $svc.Service }}/{{ .Name }}`" + `
+- Permission: {{ if .Permission }}` + "`{{ .Permission }}`" + `{{ else }}public{{ end }}
+- Request: [{{ .Request }}](#{{ anchor .Request }})
+- Response: [{{ .Response }}](#{{ anchor .Response }})
+{{ end }}
+## Types
+{{ range .Types }}
+### {{ .Name }}
+{{ if .Description }}
+{{ .Description }}
+{{ end }}
+| Field | JSON | Type | Description |
+|-------|------|------|-------------|
+{{- range .Fields }}
+| {{ .Name }} | ` + "`{{ .JSON }}`" + ` | ` + "`{{ .Type }}`" + ` | {{ .Description }} |
+{{- end }}
+{{- if .Response }}
+| Code | ` + "`code`" + ` | ` + "`Code`" + ` | Result code. ` + "`0`" + ` on success |
+| Error | ` + "`error`" + ` | ` + "`ErrorResponse`" + ` | Set when the call failed |
+{{- end }}
+{{ end -}}
+`))
@@ -0,0 +1,113 @@
+package rpcgen
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestGenerateGolden regenerate every service schema and compare with the checked in files.
+// Run go generate ./internal/... when it fails after a schema or template change
+func TestGenerateGolden(t *testing.T) {
+	schemas, err := filepath.Glob("../../internal/*/*.rpc.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schemas) == 0 {
+		t.Fatal("no schema found")
+	}
+
+	for _, schemaPath := range schemas {
+		t.Run(filepath.Base(schemaPath), func(t *testing.T) {
+			s, err := Load(schemaPath)
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+
+			files, err := Generate(s)
+			if err != nil {
+				t.Fatalf("generate: %v", err)
+			}
+
+			for _, f := range files {
+				want, err := os.ReadFile(f.Path)
+				if err != nil {
+					t.Fatalf("read %s: %v", f.Path, err)
+				}
+				if !bytes.Equal(f.Content, want) {
+					t.Errorf("%s is stale, run go generate", f.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	base := func() Schema {
+		return Schema{
+			Service: "TestService",
+			Domain:  "test",
+			Package: "test",
+			Types: []Type{
+				{Name: "GetReq"},
+				{Name: "GetResp", Fields: []Field{{Name: "OwnerURL", Type: "string"}}},
+			},
+			Rpcs: []Rpc{{Name: "Get", Request: "GetReq", Response: "GetResp"}},
+		}
+	}
+
+	s := base()
+	if err := s.Validate(); err != nil {
+		t.Fatalf("valid schema: %v", err)
+	}
+	rpc := s.Rpcs[0]
+	if rpc.Action != "get" || rpc.Permission != "test:get" || rpc.Method != "POST" {
+		t.Fatalf("defaults not applied: %+v", rpc)
+	}
+	if json := s.Types[1].Fields[0].JSON; json != "ownerURL" {
+		t.Fatalf("json name %q, want ownerURL", json)
+	}
+	if !s.Types[1].Response {
+		t.Fatal("response type not marked")
+	}
+
+	s = base()
+	s.Rpcs[0].Permission = "public"
+	if err := s.Validate(); err != nil || s.Rpcs[0].Permission != "" {
+		t.Fatalf("public rpc: %v, permission %q", err, s.Rpcs[0].Permission)
+	}
+
+	s = base()
+	s.Types[1].Fields[0].Type = "orbital.RouteInfo"
+	if err := s.Validate(); err != nil {
+		t.Fatalf("qualified type without client: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(s *Schema)
+	}{
+		{"lower case service", func(s *Schema) { s.Service = "testService" }},
+		{"missing domain", func(s *Schema) { s.Domain = "" }},
+		{"duplicate type", func(s *Schema) { s.Types = append(s.Types, Type{Name: "GetReq"}) }},
+		{"unknown field type", func(s *Schema) { s.Types[1].Fields[0].Type = "[]Missing" }},
+		{"unknown request", func(s *Schema) { s.Rpcs[0].Request = "Missing" }},
+		{"duplicate rpc", func(s *Schema) { s.Rpcs = append(s.Rpcs, s.Rpcs[0]) }},
+		{"qualified type with client", func(s *Schema) {
+			s.Types[1].Fields[0].Type = "orbital.RouteInfo"
+			s.Output.Client = "client_gen.go"
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := base()
+			tt.mutate(&s)
+			if err := s.Validate(); !errors.Is(err, ErrSchemaInvalid) {
+				t.Fatalf("want ErrSchemaInvalid, got %v", err)
+			}
+		})
+	}
+}
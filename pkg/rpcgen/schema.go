@@ -0,0 +1,248 @@
+package rpcgen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema describe one RPC service.
+// A single schema produces the server registration, the wasm client and the documentation
+type Schema struct {
+	Service     string   `yaml:"service"`     // Service name used in the route path: /rpc/<Service>/<Rpc>
+	Domain      string   `yaml:"domain"`      // Message metadata domain
+	Package     string   `yaml:"package"`     // Go package of the server file
+	Description string   `yaml:"description"` // Free text added to the docs and the interface comment
+	Imports     []string `yaml:"imports"`     // Extra imports required by the middlewares
+	Middlewares []string `yaml:"middlewares"` // Go expressions evaluated at registration. `server` is in scope
+	Output      Output   `yaml:"output"`
+	Types       []Type   `yaml:"types"`
+	Rpcs        []Rpc    `yaml:"rpcs"`
+
+	path string
+}
+
+// Output paths relative to the schema file. Empty paths are skipped
+type Output struct {
+	Server        string `yaml:"server"`
+	Client        string `yaml:"client"`
+	ClientPackage string `yaml:"clientPackage"`
+	Docs          string `yaml:"docs"`
+}
+
+type Type struct {
+	Name        string  `yaml:"name"`
+	Description string  `yaml:"description"`
+	Fields      []Field `yaml:"fields"`
+
+	// Response is set for types used as an RPC response.
+	// These receive the standard code and error fields
+	Response bool `yaml:"-"`
+}
+
+type Field struct {
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type"` // Builtin, schema type or <package>.<Type> of an import. Qualified types need no client output
+	JSON        string `yaml:"json"`
+	OmitEmpty   bool   `yaml:"omitEmpty"`
+	Description string `yaml:"description"`
+}
+
+type Rpc struct {
+	Name        string `yaml:"name"`
+	Action      string `yaml:"action"`
+	Method      string `yaml:"method"`
+	Permission  string `yaml:"permission"` // Defaults to <domain>:<action>. Use "public" for none
+	Description string `yaml:"description"`
+	Request     string `yaml:"request"`
+	Response    string `yaml:"response"`
+}
+
+var (
+	identRe = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+	typeRe  = regexp.MustCompile(`^(\[\]|\*|map\[string\])*([a-z][a-z0-9]*\.[A-Z][A-Za-z0-9]*|[A-Za-z][A-Za-z0-9]*)$`)
+)
+
+var builtinTypes = map[string]bool{
+	"string": true, "bool": true, "any": true,
+	"int": true, "int32": true, "int64": true,
+	"uint": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true, "byte": true,
+}
+
+// Load read and validate a schema file
+func Load(schemaPath string) (*Schema, error) {
+	raw, err := os.ReadFile(schemaPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w:[%v]", ErrSchemaRead, err)
+	}
+
+	var s Schema
+	if err = yaml.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("%w:[%v]", ErrSchemaRead, err)
+	}
+
+	s.path = schemaPath
+	if err = s.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// Validate the schema and apply defaults
+func (s *Schema) Validate() error {
+	if !identRe.MatchString(s.Service) {
+		return fmt.Errorf("%w:[service: %q]", ErrSchemaInvalid, s.Service)
+	}
+
+	if s.Domain == "" {
+		return fmt.Errorf("%w:[domain is required]", ErrSchemaInvalid)
+	}
+
+	if s.Package == "" && s.Output.Server != "" {
+		return fmt.Errorf("%w:[package is required for server output]", ErrSchemaInvalid)
+	}
+
+	if s.Output.ClientPackage == "" {
+		s.Output.ClientPackage = "service"
+	}
+
+	types := make(map[string]*Type, len(s.Types))
+	for i := range s.Types {
+		t := &s.Types[i]
+		if !identRe.MatchString(t.Name) {
+			return fmt.Errorf("%w:[type: %q]", ErrSchemaInvalid, t.Name)
+		}
+
+		if _, found := types[t.Name]; found {
+			return fmt.Errorf("%w:[duplicate type: %s]", ErrSchemaInvalid, t.Name)
+		}
+		types[t.Name] = t
+
+		for j := range t.Fields {
+			f := &t.Fields[j]
+			if !identRe.MatchString(f.Name) {
+				return fmt.Errorf("%w:[field: %s.%q]", ErrSchemaInvalid, t.Name, f.Name)
+			}
+
+			if f.JSON == "" {
+				f.JSON = lowerFirst(f.Name)
+			}
+		}
+	}
+
+	// Field types can only be resolved once all types are known
+	for _, t := range s.Types {
+		for _, f := range t.Fields {
+			qualified, err := checkType(f.Type, types)
+			if err != nil {
+				return fmt.Errorf("%w:[%s.%s: %v]", ErrSchemaInvalid, t.Name, f.Name, err)
+			}
+
+			// The wasm client cannot import the node packages
+			if qualified && s.Output.Client != "" {
+				return fmt.Errorf("%w:[%s.%s: %s needs no client output]", ErrSchemaInvalid, t.Name, f.Name, f.Type)
+			}
+		}
+	}
+
+	seen := make(map[string]bool, len(s.Rpcs))
+	for i := range s.Rpcs {
+		rpc := &s.Rpcs[i]
+		if !identRe.MatchString(rpc.Name) {
+			return fmt.Errorf("%w:[rpc: %q]", ErrSchemaInvalid, rpc.Name)
+		}
+
+		if seen[rpc.Name] {
+			return fmt.Errorf("%w:[duplicate rpc: %s]", ErrSchemaInvalid, rpc.Name)
+		}
+		seen[rpc.Name] = true
+
+		if rpc.Action == "" {
+			rpc.Action = lowerFirst(rpc.Name)
+		}
+
+		switch rpc.Permission {
+		case "":
+			rpc.Permission = s.Domain + ":" + rpc.Action
+		case "public":
+			rpc.Permission = ""
+		}
+
+		if rpc.Method == "" {
+			rpc.Method = "POST"
+		}
+		rpc.Method = strings.ToUpper(rpc.Method)
+
+		if _, found := types[rpc.Request]; !found {
+			return fmt.Errorf("%w:[rpc %s: unknown request type %q]", ErrSchemaInvalid, rpc.Name, rpc.Request)
+		}
+
+		res, found := types[rpc.Response]
+		if !found {
+			return fmt.Errorf("%w:[rpc %s: unknown response type %q]", ErrSchemaInvalid, rpc.Name, rpc.Response)
+		}
+		res.Response = true
+	}
+
+	return nil
+}
+
+// resolve an output path relative to the schema file
+func (s *Schema) resolve(p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+
+	return filepath.Join(filepath.Dir(s.path), p)
+}
+
+// checkType report whether the type is qualified by a package, those are checked by the compiler
+func checkType(t string, types map[string]*Type) (bool, error) {
+	m := typeRe.FindStringSubmatch(t)
+	if m == nil {
+		return false, fmt.Errorf("unsupported type %q", t)
+	}
+
+	base := m[2]
+	if strings.Contains(base, ".") {
+		return true, nil
+	}
+	if builtinTypes[base] {
+		return false, nil
+	}
+
+	if _, found := types[base]; !found {
+		return false, fmt.Errorf("unknown type %q", base)
+	}
+
+	return false, nil
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+
+	// Keep acronyms readable: ID -> id, URL -> url, OwnerURL -> ownerURL
+	runes := []rune(s)
+	i := 0
+	for i < len(runes) && unicode.IsUpper(runes[i]) {
+		i++
+	}
+
+	switch {
+	case i == len(runes):
+		return strings.ToLower(s)
+	case i > 1:
+		i--
+	}
+
+	return strings.ToLower(string(runes[:i])) + string(runes[i:])
+}
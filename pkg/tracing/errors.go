@@ -0,0 +1,8 @@
+package tracing
+
+import "errors"
+
+var (
+	ErrExporter        = errors.New("cannot create span exporter")
+	ErrUnknownExporter = errors.New("unknown span exporter")
+)
@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	ExporterNone   = ""
+	ExporterStdout = "stdout"
+)
+
+// Config span export. Spans are created anyway, they are only dropped without exporter
+type Config struct {
+	Exporter    string  `yaml:"exporter"`              // stdout or empty to disable
+	SampleRatio float64 `yaml:"sampleRatio,omitempty"` // Ratio of root spans kept. Defaults to 1
+	Pretty      bool    `yaml:"pretty,omitempty"`      // Indent the stdout output
+}
+
+// Setup install the tracer provider and the W3C trace context propagator.
+// The returned func flushes the pending spans
+func Setup(cfg *Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	if cfg == nil || cfg.Exporter == ExporterNone {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var exporter sdktrace.SpanExporter
+	switch cfg.Exporter {
+	case ExporterStdout:
+		opts := []stdouttrace.Option{stdouttrace.WithWriter(os.Stdout)}
+		if cfg.Pretty {
+			opts = append(opts, stdouttrace.WithPrettyPrint())
+		}
+
+		exp, err := stdouttrace.New(opts...)
+		if err != nil {
+			return nil, fmt.Errorf("%w:[%v]", ErrExporter, err)
+		}
+		exporter = exp
+	default:
+		return nil, fmt.Errorf("%w:[%s]", ErrUnknownExporter, cfg.Exporter)
+	}
+
+	ratio := cfg.SampleRatio
+	if ratio <= 0 {
+		ratio = 1
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", "orbital"))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
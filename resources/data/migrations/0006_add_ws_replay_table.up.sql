@@ -0,0 +1,8 @@
+CREATE TABLE ws_replay
+(
+    topic      TEXT    NOT NULL,
+    seq        INTEGER NOT NULL,
+    message    BLOB    NOT NULL, -- Signed message as sent on the wire
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (topic, seq)
+);
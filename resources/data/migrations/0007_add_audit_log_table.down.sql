@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS audit_log_no_update;
+DROP TABLE IF EXISTS audit_anchor;
+DROP TABLE IF EXISTS audit_log;
@@ -0,0 +1,36 @@
+DROP TABLE IF EXISTS audit_log;
+CREATE TABLE audit_log
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    ts          INTEGER NOT NULL, -- Unix nanoseconds
+    public_key  TEXT    NOT NULL DEFAULT '',
+    user_id     TEXT    NOT NULL DEFAULT '',
+    service     TEXT    NOT NULL,
+    action      TEXT    NOT NULL,
+    params_hash TEXT    NOT NULL DEFAULT '', -- SHA-256 of the request body
+    code        INTEGER NOT NULL,
+    remote_addr TEXT    NOT NULL DEFAULT '',
+    transport   TEXT    NOT NULL DEFAULT '',
+    request_id  TEXT    NOT NULL DEFAULT '',
+    prev_hash   TEXT    NOT NULL,
+    hash        TEXT    NOT NULL
+);
+
+CREATE INDEX audit_log_ts ON audit_log (ts);
+CREATE INDEX audit_log_public_key ON audit_log (public_key);
+
+-- Entries are never modified. Retention deletes the oldest ones and moves the anchor
+CREATE TRIGGER audit_log_no_update
+    BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append only');
+END;
+
+-- Last pruned entry. Verification of the chain starts from it
+DROP TABLE IF EXISTS audit_anchor;
+CREATE TABLE audit_anchor
+(
+    singleton INTEGER PRIMARY KEY CHECK (singleton = 1),
+    last_id   INTEGER NOT NULL,
+    last_hash TEXT    NOT NULL
+);
@@ -0,0 +1,51 @@
+// Command assetgen prepare the embedded web UI: content hashed copies of the
+// entry files, the manifest and the gzip/brotli variants. Run after the wasm build.
+//
+//	go run ./tools/assetgen orbital/web
+package main
+
+import (
+	"flag"
+	"fmt"
+	"orbital/pkg/assets"
+	"os"
+	"strings"
+)
+
+func main() {
+	entries := flag.String("hash", "orbital.wasm,wasm_exec.js,orbital.css", "Comma separated files to fingerprint")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: assetgen [-hash files] <dir>\n")
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := flag.Arg(0)
+
+	manifest, err := assets.Fingerprint(dir, strings.Split(*entries, ","))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "assetgen: %s\n", err)
+		os.Exit(1)
+	}
+
+	// The server rewrites index.html and serves the entries from their hashed copy
+	exclude := []string{"index.html"}
+	for name := range manifest {
+		exclude = append(exclude, name)
+	}
+
+	written, err := assets.Precompress(dir, exclude...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "assetgen: %s\n", err)
+		os.Exit(1)
+	}
+
+	for name, hashed := range manifest {
+		fmt.Printf("- %s -> %s\n", name, hashed)
+	}
+	fmt.Printf("- %d compressed variants\n", written)
+}
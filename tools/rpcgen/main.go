@@ -0,0 +1,46 @@
+// Command rpcgen generate RPC glue from service schemas.
+// Same as `orbital gen` but without depending on the services, so it works
+// from go:generate even when the generated files are missing or stale.
+//
+//	//go:generate go run orbital/tools/rpcgen apps.rpc.yaml
+package main
+
+import (
+	"flag"
+	"fmt"
+	"orbital/pkg/rpcgen"
+	"os"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: rpcgen <schema.yaml>...\n")
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	for _, schemaPath := range flag.Args() {
+		if err := generate(schemaPath); err != nil {
+			fmt.Fprintf(os.Stderr, "rpcgen: %s\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+func generate(schemaPath string) error {
+	schema, err := rpcgen.Load(schemaPath)
+	if err != nil {
+		return err
+	}
+
+	files, err := rpcgen.Generate(schema)
+	if err != nil {
+		return err
+	}
+
+	return rpcgen.Write(files)
+}
@@ -2,6 +2,7 @@ package components
 
 import (
 	"bytes"
+	"errors"
 	"orbital/web/wasm/orbital"
 	"orbital/web/wasm/pkg/dom"
 	"orbital/web/wasm/pkg/transport"
@@ -96,6 +97,12 @@ func (comp *LoginComponent) uiEventLogin(_ js.Value, args []js.Value) any {
 		})
 
 		if err != nil {
+			var apiErr *transport.Error
+			if errors.As(err, &apiErr) {
+				comp.renderError(apiErr.Type, apiErr.Msg)
+				return
+			}
+
 			comp.renderError("auth.failed", err.Error())
 			return
 		}
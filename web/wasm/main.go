@@ -26,21 +26,19 @@ func boot() {
 
 	deps.Events.Once("orbital:ready", ready)
 
-	retries := 3
-	interval := 1 * time.Second
-
+	// Ready on the first open connection, websocket or event stream fallback.
+	// Hooks run on the js event loop, ready must not block it
 	var async transport.Async
-	async.Async(func() {
-		for i := 0; i < retries; i++ {
-			if deps.Ws.IsOpen() {
-				deps.Events.Emit("orbital:ready", deps)
-				return
-			}
+	deps.Ws.OnOpen(func() {
+		async.Async(func() {
+			deps.Events.Emit("orbital:ready", deps)
+		})
+	})
 
-			time.Sleep(interval)
+	time.AfterFunc(30*time.Second, func() {
+		if !deps.Ws.IsOpen() {
+			dom.ConsoleError("[orbital] still waiting for a connection to the node")
 		}
-
-		dom.ConsoleError("[orbital] cannot open websocket connection")
 	})
 }
 
@@ -53,6 +51,8 @@ func ready(di *orbital.Dependency) {
 		return
 	}
 
+	di.Ws.SetSigner(service.StoredSigner(di.Storage))
+
 	authSvc := service.NewAuthService(di)
 	if err := di.RegisterService(service.AuthServiceKey, authSvc); err != nil {
 		dom.ConsoleError("[orbital] cannot register service", service.AuthServiceKey)
@@ -93,7 +93,7 @@ func (api *API) doCall(data []byte, headers map[string]string) ([]byte, error) {
 	}
 
 	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected response status code: %d, body: %s", response.StatusCode, body)
+		return nil, DecodeError(response.StatusCode, body)
 	}
 
 	return body, nil
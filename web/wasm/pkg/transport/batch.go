@@ -0,0 +1,107 @@
+package transport
+
+import (
+	"encoding/json"
+	"orbital/pkg/cryptographer"
+	"strings"
+)
+
+type (
+	batchCall struct {
+		Service string          `json:"service"`
+		Action  string          `json:"action"`
+		Body    json.RawMessage `json:"body,omitempty"`
+	}
+
+	batchResult struct {
+		Code Code            `json:"code"`
+		Body json.RawMessage `json:"body"`
+	}
+
+	batchResp struct {
+		Results []batchResult `json:"results"`
+	}
+)
+
+// Batch collect calls and send them in one signed request to rpc/batch.
+// Each call result is decoded in the res given to Add
+type Batch struct {
+	signer   SignerFunc
+	parallel bool
+	calls    []batchCall
+	results  []any
+	err      error
+}
+
+// NewBatch create a batch. Parallel lets the node run the calls concurrently
+func NewBatch(signer SignerFunc, parallel bool) *Batch {
+	return &Batch{
+		signer:   signer,
+		parallel: parallel,
+	}
+}
+
+// Add queue a call. The path is the route path: rpc/<Service>/<Action>
+func (b *Batch) Add(path string, req any, res any) *Batch {
+	service, action, found := strings.Cut(strings.TrimPrefix(path, "rpc/"), "/")
+	if !found && b.err == nil {
+		b.err = &Error{Code: InvalidRequest, Type: "transport.badPath", Msg: "invalid route path: " + path}
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil && b.err == nil {
+		b.err = err
+	}
+
+	b.calls = append(b.calls, batchCall{
+		Service: service,
+		Action:  action,
+		Body:    body,
+	})
+	b.results = append(b.results, res)
+
+	return b
+}
+
+// Do send the batch. The returned slice holds the error of each call in order, nil when it succeeded.
+// The error is set when the batch itself failed
+func (b *Batch) Do() ([]error, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	var res batchResp
+	err := Call("rpc/batch", b.signer, cryptographer.Metadata{
+		Domain: "rpc",
+		Action: "batch",
+	}, map[string]any{
+		"calls":    b.calls,
+		"parallel": b.parallel,
+	}, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(b.calls))
+	for i := range b.calls {
+		if i >= len(res.Results) {
+			errs[i] = &Error{Code: Internal, Type: "transport.missingResult", Msg: "missing batch result"}
+			continue
+		}
+
+		result := res.Results[i]
+		if result.Code != OK {
+			var reply ErrorReply
+			if err = json.Unmarshal(result.Body, &reply); err == nil && reply.Error != nil {
+				errs[i] = NewError(reply.Code, reply.Error)
+				continue
+			}
+		}
+
+		if err = json.Unmarshal(result.Body, b.results[i]); err != nil {
+			errs[i] = err
+		}
+	}
+
+	return errs, nil
+}
@@ -0,0 +1,38 @@
+package transport
+
+import (
+	"encoding/json"
+	"orbital/pkg/cryptographer"
+)
+
+// SignerFunc return the signer for a call.
+// Evaluated on every call so a key change is picked up without rebuilding the client
+type SignerFunc func() (cryptographer.Signer, error)
+
+// Call sign the request, post it to the rpc path and decode the verified response body into res
+func Call(path string, signer SignerFunc, meta cryptographer.Metadata, req any, res any) error {
+	sk, err := signer()
+	if err != nil {
+		return err
+	}
+
+	msg, err := cryptographer.Encode(sk, meta, req)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	api := NewAPI(path)
+	api.WithMiddleware(VerifyAndUnwrap)
+
+	rawRes, err := api.Do(raw, nil)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(rawRes, res)
+}
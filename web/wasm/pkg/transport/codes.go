@@ -3,15 +3,21 @@ package transport
 type Code uint32
 
 const (
-	OK              Code = 0
-	Canceled        Code = 1
-	Unknown         Code = 2
-	NotFound        Code = 3
-	Unimplemented   Code = 4
-	Unauthenticated Code = 5
-	Internal        Code = 6
-	Unavailable     Code = 7
-	InvalidRequest  Code = 8
+	OK                 Code = 0
+	Canceled           Code = 1
+	Unknown            Code = 2
+	NotFound           Code = 3
+	Unimplemented      Code = 4
+	Unauthenticated    Code = 5
+	Internal           Code = 6
+	Unavailable        Code = 7
+	InvalidRequest     Code = 8
+	PermissionDenied   Code = 9
+	ResourceExhausted  Code = 10
+	DeadlineExceeded   Code = 11
+	AlreadyExists      Code = 12
+	MethodNotAllowed   Code = 13
+	FailedPrecondition Code = 14
 
 	// TODO: Add more codes as needed
 )
@@ -1,8 +1,77 @@
 package transport
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 type (
 	ErrorResponse struct {
-		Type string `json:"type"`
-		Msg  string `json:"msg"`
+		Type      string         `json:"type"`
+		Msg       string         `json:"msg"`
+		Details   map[string]any `json:"details,omitempty"`
+		Retryable bool           `json:"retryable,omitempty"`
+	}
+
+	// ErrorReply body sent by the node when a request fails
+	ErrorReply struct {
+		Code  Code           `json:"code"`
+		Error *ErrorResponse `json:"error"`
 	}
 )
+
+// Error typed error decoded from a node ErrorReply
+type Error struct {
+	Code      Code
+	Status    int // HTTP status. Zero for websocket replies
+	Type      string
+	Msg       string
+	Details   map[string]any
+	Retryable bool
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (code: %d)", e.Msg, e.Code)
+}
+
+// NewError build an Error from a reply code and its error response
+func NewError(code Code, res *ErrorResponse) *Error {
+	e := &Error{Code: code}
+	if res != nil {
+		e.Type = res.Type
+		e.Msg = res.Msg
+		e.Details = res.Details
+		e.Retryable = res.Retryable
+	}
+
+	return e
+}
+
+// DecodeError parse a signed error envelope.
+// If the payload is not a valid signed ErrorReply a generic Unknown error is returned
+func DecodeError(status int, raw []byte) *Error {
+	body, err := VerifyAndUnwrap(raw)
+	if err != nil {
+		return &Error{
+			Code:   Unknown,
+			Status: status,
+			Type:   "transport.unexpectedResponse",
+			Msg:    fmt.Sprintf("unexpected response status code: %d", status),
+		}
+	}
+
+	var reply ErrorReply
+	if err = json.Unmarshal(body, &reply); err != nil {
+		return &Error{
+			Code:   Unknown,
+			Status: status,
+			Type:   "transport.unexpectedResponse",
+			Msg:    fmt.Sprintf("cannot decode error reply: %v", err),
+		}
+	}
+
+	e := NewError(reply.Code, reply.Error)
+	e.Status = status
+
+	return e
+}
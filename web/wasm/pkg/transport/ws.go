@@ -31,12 +31,26 @@ type WsConn struct {
 	lastPong                                    time.Time
 	onOpenFn, onCloseFn, onMessageFn, onErrorFn js.Func
 	kaCancelFn                                  context.CancelFunc
+	signer                                      SignerFunc
+	pending                                     map[string]chan cryptographer.Message
+	subscriptions                               map[string]struct{}
+	welcome                                     WelcomeMessage
+	lastSeq                                     map[string]uint64 // Last sequence seen per published topic
+	resync                                      []ResyncFunc
+	onOpenHooks                                 []func()
+	failedOpens                                 int  // Websocket attempts closed before opening
+	useSSE                                      bool // Fallback transport when websockets are blocked
+	eventSource                                 js.Value
+	onSSEMessageFn, onSSEErrorFn                js.Func
 }
 
 func NewWsConn(binaryMode bool) *WsConn {
 
 	wsConn := &WsConn{
 		topics:               make(map[string]HandlerFunc),
+		pending:              make(map[string]chan cryptographer.Message),
+		subscriptions:        make(map[string]struct{}),
+		lastSeq:              make(map[string]uint64),
 		isOpen:               false,
 		allowsBinary:         binaryMode,
 		reconnect:            true,
@@ -55,6 +69,18 @@ func (ws *WsConn) IsOpen() bool {
 	return ws.isOpen
 }
 
+// OnOpen register a hook called every time the connection opens, on either transport
+func (ws *WsConn) OnOpen(fn func()) {
+	ws.mu.Lock()
+	ws.onOpenHooks = append(ws.onOpenHooks, fn)
+	open := ws.isOpen
+	ws.mu.Unlock()
+
+	if open {
+		fn()
+	}
+}
+
 func (ws *WsConn) Send(msg cryptographer.Message) {
 	if !ws.isOpen {
 		dom.ConsoleWarn("WebSocket closed")
@@ -67,6 +93,11 @@ func (ws *WsConn) Send(msg cryptographer.Message) {
 		return
 	}
 
+	if ws.useSSE {
+		ws.sendSSE(raw)
+		return
+	}
+
 	if ws.allowsBinary {
 		ws.sendBinary(raw)
 		return
@@ -80,6 +111,15 @@ func (ws *WsConn) On(topic string, handler HandlerFunc) {
 }
 
 func (ws *WsConn) connect() {
+	if !ws.useSSE && ws.failedOpens >= sseFallbackAfter {
+		ws.useSSE = true
+	}
+
+	if ws.useSSE {
+		ws.connectSSE()
+		return
+	}
+
 	wsURL := createWebSocketURL()
 	socket := js.Global().Get("WebSocket").New(wsURL)
 	ws.client = socket
@@ -104,18 +144,28 @@ func (ws *WsConn) onOpen(_ js.Value, _ []js.Value) any {
 	ws.mu.Lock()
 	ws.isOpen = true
 	ws.reconnectAttempts = 0
+	ws.failedOpens = 0
 	ws.lastPong = time.Now()
+	hooks := ws.onOpenHooks
 	ws.mu.Unlock()
 
+	// The subscriptions are restored once the welcome arrives, after the connection authenticates
 	ws.startKeepAlive()
 
+	for _, fn := range hooks {
+		fn()
+	}
+
 	return nil
 }
 
 func (ws *WsConn) onClose(_ js.Value, _ []js.Value) any {
 	ws.stopKeepAlive()
 
-	ws.isOpen = false
+	if !ws.isOpen {
+		ws.failedOpens++
+	}
+	ws.setClosed()
 
 	dom.ConsoleWarn("WebSocket connection closed")
 	if ws.reconnect {
@@ -146,7 +196,20 @@ func (ws *WsConn) onError(_ js.Value, _ []js.Value) any {
 	return nil
 }
 
+// setClosed mark the connection closed. The welcome of a closed connection can't be reused
+func (ws *WsConn) setClosed() {
+	ws.mu.Lock()
+	ws.isOpen = false
+	ws.welcome = WelcomeMessage{}
+	ws.mu.Unlock()
+}
+
 func (ws *WsConn) teardown() {
+	if ws.useSSE {
+		ws.teardownSSE()
+		return
+	}
+
 	if ws.client.Truthy() {
 		ws.client.Call("removeEventListener", "open", ws.onOpenFn)
 		ws.client.Call("removeEventListener", "close", ws.onCloseFn)
@@ -209,6 +272,10 @@ func (ws *WsConn) routeMessage(raw []byte) {
 		return
 	}
 
+	if ws.resolveCall(msg) {
+		return
+	}
+
 	t, err = topic(msg.Metadata.Domain, msg.Metadata.Action, msg.Metadata.CorrelationID)
 	if err != nil {
 		dom.ConsoleLog(err.Error())
@@ -217,8 +284,15 @@ func (ws *WsConn) routeMessage(raw []byte) {
 
 	switch t {
 	case "system/welcome":
-		// --- move this out and allow app level implementation
 		dom.ConsoleLog("[routeMessage] system welcome message", string(msg.Body))
+		ws.onWelcome(msg.Body)
+	case "system/error":
+		var reply ErrorReply
+		if err = json.Unmarshal(msg.Body, &reply); err != nil {
+			dom.ConsoleLog("[routeMessage] cannot decode error reply")
+			return
+		}
+		dom.ConsoleError("[routeMessage] system error", NewError(reply.Code, reply.Error).Error())
 	case "system/keepAlivePing":
 		ws.Send(makeKeepAlivePong())
 	case "system/keepAlivePong":
@@ -227,6 +301,8 @@ func (ws *WsConn) routeMessage(raw []byte) {
 		dom.ConsoleLog("[routeMessage] keep alive pong", ws.lastPong)
 		ws.mu.Unlock()
 	default:
+		ws.trackSeq(t, msg)
+
 		handler, exists := ws.topics[t]
 		if !exists {
 			dom.ConsoleLog("[routeMessage] topic not found", t)
@@ -271,6 +347,11 @@ func (ws *WsConn) scheduleReconnect() {
 func (ws *WsConn) startKeepAlive() {
 	ws.stopKeepAlive()
 
+	// The node pings the event stream itself and refuses unsigned posts
+	if ws.useSSE {
+		return
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	ws.kaCancelFn = cancel
 
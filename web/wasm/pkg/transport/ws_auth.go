@@ -0,0 +1,89 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"orbital/pkg/cryptographer"
+	"orbital/web/wasm/pkg/dom"
+	"syscall/js"
+)
+
+type (
+	// WelcomeMessage sent by the node when the connection opens
+	WelcomeMessage struct {
+		ConnID     string `json:"connId"`
+		ServerTime int64  `json:"serverTime"`
+	}
+
+	AuthenticateResp struct {
+		UserID string `json:"userId"`
+		Role   string `json:"role"`
+	}
+)
+
+// Authenticate bind the connection to the user by signing the welcome ConnID and ServerTime
+func (ws *WsConn) Authenticate(ctx context.Context) (*AuthenticateResp, error) {
+	ws.mu.Lock()
+	welcome := ws.welcome
+	ws.mu.Unlock()
+
+	if welcome.ConnID == "" {
+		return nil, &Error{Code: Unavailable, Type: "transport.noWelcome", Msg: "welcome message not received"}
+	}
+
+	raw, err := ws.request(ctx, cryptographer.Metadata{
+		Domain: "system",
+		Action: "authenticate",
+	}, welcome)
+	if err != nil {
+		return nil, err
+	}
+
+	var res AuthenticateResp
+	if err = json.Unmarshal(raw, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// onWelcome keep the welcome for the handshake and authenticate when a key is available
+func (ws *WsConn) onWelcome(body []byte) {
+	var welcome WelcomeMessage
+	if err := json.Unmarshal(body, &welcome); err != nil {
+		dom.ConsoleError("[onWelcome] cannot decode welcome message")
+		return
+	}
+
+	ws.mu.Lock()
+	ws.welcome = welcome
+	signer := ws.signer
+	ws.mu.Unlock()
+
+	// The event stream is usable once its connection id is known
+	if ws.useSSE && !ws.isOpen {
+		ws.onOpen(js.Null(), nil)
+	}
+
+	// Permissioned topics need an authenticated connection, so without a key the open ones are restored
+	if signer == nil {
+		ws.resubscribe()
+		return
+	}
+
+	// Not logged in yet, the login authenticates the connection
+	if _, err := signer(); err != nil {
+		ws.resubscribe()
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultCallTimeout)
+		defer cancel()
+
+		if _, err := ws.Authenticate(ctx); err != nil {
+			dom.ConsoleError("[onWelcome] cannot authenticate connection", err.Error())
+		}
+		ws.resubscribe()
+	}()
+}
@@ -0,0 +1,134 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"orbital/pkg/cryptographer"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultCallTimeout deadline used by Call when the context carries none
+const DefaultCallTimeout = 30 * time.Second
+
+// SetSigner set the signer used for calls made over the websocket
+func (ws *WsConn) SetSigner(signer SignerFunc) {
+	ws.mu.Lock()
+	ws.signer = signer
+	ws.mu.Unlock()
+}
+
+// Call a route over the websocket and wait for its reply. The topic is the route path: <Service>/<Action>.
+// Must not be called from a js callback, the reply is delivered on the event loop
+func (ws *WsConn) Call(topic string, req any) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCallTimeout)
+	defer cancel()
+
+	return ws.CallContext(ctx, topic, req)
+}
+
+// CallContext same as Call. The context deadline is sent to the node and
+// canceling the context cancels the call on the node
+func (ws *WsConn) CallContext(ctx context.Context, topic string, req any) ([]byte, error) {
+	return ws.request(ctx, cryptographer.Metadata{
+		Domain: "rpc",
+		Action: strings.TrimPrefix(topic, "rpc/"),
+	}, req)
+}
+
+// request sign and send a message with a new correlation id and wait for the reply carrying it
+func (ws *WsConn) request(ctx context.Context, meta cryptographer.Metadata, req any) ([]byte, error) {
+	if !ws.isOpen {
+		return nil, &Error{Code: Unavailable, Type: "transport.wsClosed", Msg: "websocket closed"}
+	}
+
+	ws.mu.Lock()
+	signer := ws.signer
+	ws.mu.Unlock()
+
+	if signer == nil {
+		return nil, &Error{Code: Unauthenticated, Type: "transport.signerMissing", Msg: "websocket signer not set"}
+	}
+
+	sk, err := signer()
+	if err != nil {
+		return nil, err
+	}
+
+	meta.CorrelationID = uuid.NewString()
+	meta.Tags = map[string]string{}
+	if deadline, ok := ctx.Deadline(); ok {
+		meta.Tags["timeout"] = strconv.FormatInt(time.Until(deadline).Milliseconds(), 10)
+	}
+
+	msg, err := cryptographer.Encode(sk, meta, req)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := make(chan cryptographer.Message, 1)
+	ws.mu.Lock()
+	ws.pending[meta.CorrelationID] = reply
+	ws.mu.Unlock()
+
+	defer func() {
+		ws.mu.Lock()
+		delete(ws.pending, meta.CorrelationID)
+		ws.mu.Unlock()
+	}()
+
+	ws.Send(*msg)
+
+	select {
+	case res := <-reply:
+		// Failed requests are answered with an ErrorReply, either on system/error or on the request topic
+		var errReply ErrorReply
+		if err = json.Unmarshal(res.Body, &errReply); err == nil && errReply.Code != OK && errReply.Error != nil {
+			return nil, NewError(errReply.Code, errReply.Error)
+		}
+
+		return res.Body, nil
+	case <-ctx.Done():
+		ws.Send(makeCancel(meta.CorrelationID))
+
+		code := Canceled
+		if ctx.Err() == context.DeadlineExceeded {
+			code = DeadlineExceeded
+		}
+		return nil, &Error{Code: code, Type: "transport.callAborted", Msg: ctx.Err().Error()}
+	}
+}
+
+// resolveCall deliver a reply to the pending call with the same correlation id
+func (ws *WsConn) resolveCall(msg cryptographer.Message) bool {
+	if msg.Metadata.CorrelationID == "" {
+		return false
+	}
+
+	ws.mu.Lock()
+	reply, found := ws.pending[msg.Metadata.CorrelationID]
+	ws.mu.Unlock()
+
+	if !found {
+		return false
+	}
+
+	reply <- msg
+	return true
+}
+
+// makeCancel creates an unsigned message asking the node to cancel a call
+func makeCancel(cid string) cryptographer.Message {
+	return cryptographer.Message{
+		V:         0,
+		Timestamp: cryptographer.Now(),
+		Metadata: cryptographer.Metadata{
+			Domain:        "system",
+			Action:        "cancel",
+			CorrelationID: cid,
+		},
+	}
+}
@@ -0,0 +1,96 @@
+package transport
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"orbital/web/wasm/pkg/dom"
+	"syscall/js"
+)
+
+const (
+	// sseFallbackAfter websocket attempts failing before opening are followed by the SSE fallback
+	sseFallbackAfter = 2
+
+	ssePath = "/sse"
+
+	// eventSourceClosed EventSource.readyState once the browser gave up reconnecting
+	eventSourceClosed = 2
+)
+
+// IsFallback report whether the connection uses the SSE transport
+func (ws *WsConn) IsFallback() bool {
+	return ws.useSSE
+}
+
+// connectSSE open the event stream. The node pushes messages on it, the client POSTs its own
+func (ws *WsConn) connectSSE() {
+	dom.ConsoleWarn("[connectSSE] websocket unavailable, using event stream")
+
+	source := js.Global().Get("EventSource").New(ssePath)
+	ws.eventSource = source
+
+	ws.onSSEMessageFn = js.FuncOf(ws.onSSEMessage)
+	ws.onSSEErrorFn = js.FuncOf(ws.onSSEError)
+
+	source.Call("addEventListener", "message", ws.onSSEMessageFn)
+	source.Call("addEventListener", "error", ws.onSSEErrorFn)
+}
+
+func (ws *WsConn) onSSEMessage(_ js.Value, args []js.Value) any {
+	ws.handleTextMessage(args[0].Get("data"))
+	return nil
+}
+
+// onSSEError the browser reconnects the stream by itself unless it is closed
+func (ws *WsConn) onSSEError(_ js.Value, _ []js.Value) any {
+	ws.stopKeepAlive()
+	ws.setClosed()
+
+	if ws.eventSource.Get("readyState").Int() == eventSourceClosed && ws.reconnect {
+		ws.scheduleReconnect()
+	}
+
+	return nil
+}
+
+func (ws *WsConn) teardownSSE() {
+	if !ws.eventSource.Truthy() {
+		return
+	}
+
+	ws.eventSource.Call("removeEventListener", "message", ws.onSSEMessageFn)
+	ws.eventSource.Call("removeEventListener", "error", ws.onSSEErrorFn)
+	ws.eventSource.Call("close")
+	ws.eventSource = js.Undefined()
+
+	ws.onSSEMessageFn.Release()
+	ws.onSSEErrorFn.Release()
+}
+
+// sendSSE POST a message for the stream connection announced by the welcome message
+func (ws *WsConn) sendSSE(raw []byte) {
+	ws.mu.Lock()
+	connID := ws.welcome.ConnID
+	ws.mu.Unlock()
+
+	if connID == "" {
+		dom.ConsoleWarn("[sendSSE] event stream not ready")
+		return
+	}
+
+	go func() {
+		res, err := http.Post(ssePath+"?conn="+url.QueryEscape(connID), "application/json", bytes.NewReader(raw))
+		if err != nil {
+			dom.ConsoleError("[sendSSE] cannot send message", err.Error())
+			return
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusAccepted {
+			body, _ := io.ReadAll(res.Body)
+			dom.ConsoleError("[sendSSE] message rejected", DecodeError(res.StatusCode, body).Error())
+		}
+	}()
+}
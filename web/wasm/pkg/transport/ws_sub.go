@@ -0,0 +1,135 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"orbital/pkg/cryptographer"
+	"orbital/web/wasm/pkg/dom"
+	"strconv"
+)
+
+// SubscribeResp the patterns the connection is subscribed to after the change.
+// Resync lists the topics whose missed messages could not be replayed
+type SubscribeResp struct {
+	Topics []string `json:"topics"`
+	Resync []string `json:"resync,omitempty"`
+}
+
+// ResyncFunc called when the missed messages of a topic are lost. The app must reload the topic state
+type ResyncFunc func(topic string)
+
+// Subscribe ask the node to publish the topics matching the patterns to this connection.
+// Subscriptions are restored when the connection reopens
+func (ws *WsConn) Subscribe(ctx context.Context, patterns ...string) ([]string, error) {
+	topics, err := ws.subscription(ctx, "subscribe", patterns, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.mu.Lock()
+	for _, p := range patterns {
+		ws.subscriptions[p] = struct{}{}
+	}
+	ws.mu.Unlock()
+
+	return topics, nil
+}
+
+// Unsubscribe stop receiving the topics matching the patterns
+func (ws *WsConn) Unsubscribe(ctx context.Context, patterns ...string) ([]string, error) {
+	ws.mu.Lock()
+	for _, p := range patterns {
+		delete(ws.subscriptions, p)
+	}
+	ws.mu.Unlock()
+
+	return ws.subscription(ctx, "unsubscribe", patterns, nil)
+}
+
+// OnResync register the handler called when a topic must be reloaded after a reconnect
+func (ws *WsConn) OnResync(fn ResyncFunc) {
+	ws.mu.Lock()
+	ws.resync = append(ws.resync, fn)
+	ws.mu.Unlock()
+}
+
+func (ws *WsConn) subscription(ctx context.Context, action string, patterns []string, since map[string]uint64) ([]string, error) {
+	raw, err := ws.request(ctx, cryptographer.Metadata{
+		Domain: "system",
+		Action: action,
+	}, struct {
+		Topics []string          `json:"topics"`
+		Since  map[string]uint64 `json:"since,omitempty"`
+	}{Topics: patterns, Since: since})
+	if err != nil {
+		return nil, err
+	}
+
+	var res SubscribeResp
+	if err = json.Unmarshal(raw, &res); err != nil {
+		return nil, err
+	}
+
+	if len(res.Resync) > 0 {
+		ws.mu.Lock()
+		handlers := ws.resync
+		for _, t := range res.Resync {
+			delete(ws.lastSeq, t)
+		}
+		ws.mu.Unlock()
+
+		for _, t := range res.Resync {
+			for _, fn := range handlers {
+				fn(t)
+			}
+		}
+	}
+
+	return res.Topics, nil
+}
+
+// trackSeq remember the last sequence received on a published topic
+func (ws *WsConn) trackSeq(t string, msg cryptographer.Message) {
+	raw, found := msg.Metadata.Tags["seq"]
+	if !found {
+		return
+	}
+
+	seq, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return
+	}
+
+	ws.mu.Lock()
+	ws.lastSeq[t] = seq
+	ws.mu.Unlock()
+}
+
+// resubscribe restore the subscriptions on a new connection and ask for the messages missed while offline.
+// The node drops the subscriptions on disconnect
+func (ws *WsConn) resubscribe() {
+	ws.mu.Lock()
+	patterns := make([]string, 0, len(ws.subscriptions))
+	for p := range ws.subscriptions {
+		patterns = append(patterns, p)
+	}
+
+	since := make(map[string]uint64, len(ws.lastSeq))
+	for t, seq := range ws.lastSeq {
+		since[t] = seq
+	}
+	ws.mu.Unlock()
+
+	if len(patterns) == 0 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultCallTimeout)
+		defer cancel()
+
+		if _, err := ws.subscription(ctx, "subscribe", patterns, since); err != nil {
+			dom.ConsoleError("[resubscribe] cannot restore subscriptions", err.Error())
+		}
+	}()
+}
@@ -0,0 +1,59 @@
+// Code generated by orbital gen. DO NOT EDIT.
+// Source: AppsService schema
+
+package service
+
+import (
+	"orbital/pkg/cryptographer"
+	"orbital/web/wasm/pkg/transport"
+)
+
+// App struct holder for apps
+type App struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Icon        string   `json:"icon"`
+	Version     string   `json:"version"`
+	Description string   `json:"description"`
+	Namespace   string   `json:"namespace"`
+	OwnerKey    string   `json:"ownerKey"`
+	OwnerURL    string   `json:"ownerUrl"`
+	Labels      []string `json:"labels"`
+	IsExternal  bool     `json:"isExternal"`
+	Apps        []App    `json:"apps"` // If an app it's a suite of apps (just a group basically)
+}
+
+// ListReq filters the listed apps.
+type ListReq struct {
+}
+
+type ListResp struct {
+	Apps  []App                    `json:"apps"`
+	Code  transport.Code           `json:"code"`
+	Error *transport.ErrorResponse `json:"error,omitempty"`
+}
+
+// AppsServiceClient typed client for AppsService
+type AppsServiceClient struct {
+	signer transport.SignerFunc
+}
+
+func NewAppsServiceClient(signer transport.SignerFunc) *AppsServiceClient {
+	return &AppsServiceClient{
+		signer: signer,
+	}
+}
+
+// List the enabled standalone apps with their children.
+func (c *AppsServiceClient) List(req ListReq) (*ListResp, error) {
+	var res ListResp
+	err := transport.Call("rpc/AppsService/List", c.signer, cryptographer.Metadata{
+		Domain: "apps",
+		Action: "list",
+	}, req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
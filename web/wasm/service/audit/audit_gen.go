@@ -0,0 +1,95 @@
+// Code generated by orbital gen. DO NOT EDIT.
+// Source: AuditService schema
+
+package audit
+
+import (
+	"orbital/pkg/cryptographer"
+	"orbital/web/wasm/pkg/transport"
+)
+
+// Entry one recorded action
+type Entry struct {
+	ID         int64  `json:"id"`
+	Time       int64  `json:"time"`      // Unix nanoseconds
+	PublicKey  string `json:"publicKey"` // Verified signer. Empty for public routes
+	UserID     string `json:"userId"`
+	Service    string `json:"service"`
+	Action     string `json:"action"`
+	ParamsHash string `json:"paramsHash"` // SHA-256 of the request body
+	Code       uint32 `json:"code"`       // Reply code
+	RemoteAddr string `json:"remoteAddr"`
+	Transport  string `json:"transport"` // http, batch or ws
+	RequestID  string `json:"requestId"`
+	PrevHash   string `json:"prevHash"`
+	Hash       string `json:"hash"`
+}
+
+type QueryReq struct {
+	PublicKey string  `json:"publicKey,omitempty"`
+	UserID    string  `json:"userId,omitempty"`
+	Service   string  `json:"service,omitempty"`
+	Action    string  `json:"action,omitempty"`
+	Code      *uint32 `json:"code,omitempty"`    // Only entries with this reply code
+	Since     int64   `json:"since,omitempty"`   // Unix seconds, inclusive
+	Until     int64   `json:"until,omitempty"`   // Unix seconds, exclusive
+	AfterID   int64   `json:"afterId,omitempty"` // Cursor: the next value of the previous page
+	Limit     int     `json:"limit,omitempty"`   // Defaults to 100, at most 1000
+}
+
+type QueryResp struct {
+	Entries []Entry                  `json:"entries"`
+	Next    int64                    `json:"next"` // Cursor of the next page. Zero on the last page
+	Code    transport.Code           `json:"code"`
+	Error   *transport.ErrorResponse `json:"error,omitempty"`
+}
+
+type VerifyReq struct {
+}
+
+type VerifyResp struct {
+	Checked int                      `json:"checked"` // Entries checked before the first broken one
+	Valid   bool                     `json:"valid"`
+	Broken  string                   `json:"broken,omitempty"` // Why the chain is broken
+	Code    transport.Code           `json:"code"`
+	Error   *transport.ErrorResponse `json:"error,omitempty"`
+}
+
+// AuditServiceClient typed client for AuditService
+type AuditServiceClient struct {
+	signer transport.SignerFunc
+}
+
+func NewAuditServiceClient(signer transport.SignerFunc) *AuditServiceClient {
+	return &AuditServiceClient{
+		signer: signer,
+	}
+}
+
+// Query the audit log, oldest entries first.
+func (c *AuditServiceClient) Query(req QueryReq) (*QueryResp, error) {
+	var res QueryResp
+	err := transport.Call("rpc/AuditService/Query", c.signer, cryptographer.Metadata{
+		Domain: "audit",
+		Action: "query",
+	}, req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// Verify Check the hash chain of the audit log.
+func (c *AuditServiceClient) Verify(req VerifyReq) (*VerifyResp, error) {
+	var res VerifyResp
+	err := transport.Call("rpc/AuditService/Verify", c.signer, cryptographer.Metadata{
+		Domain: "audit",
+		Action: "verify",
+	}, req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
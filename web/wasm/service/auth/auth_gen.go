@@ -0,0 +1,73 @@
+// Code generated by orbital gen. DO NOT EDIT.
+// Source: AuthService schema
+
+package auth
+
+import (
+	"orbital/pkg/cryptographer"
+	"orbital/web/wasm/pkg/transport"
+)
+
+type User struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	PublicKey string `json:"publicKey"`
+	Access    string `json:"access"`
+}
+
+// AuthReq is empty, the user is the one holding the envelope key.
+type AuthReq struct {
+}
+
+type AuthResp struct {
+	User  *User                    `json:"user"`
+	Code  transport.Code           `json:"code"`
+	Error *transport.ErrorResponse `json:"error,omitempty"`
+}
+
+type CheckReq struct {
+}
+
+type CheckResp struct {
+	Code  transport.Code           `json:"code"`
+	Error *transport.ErrorResponse `json:"error,omitempty"`
+}
+
+// AuthServiceClient typed client for AuthService
+type AuthServiceClient struct {
+	signer transport.SignerFunc
+}
+
+func NewAuthServiceClient(signer transport.SignerFunc) *AuthServiceClient {
+	return &AuthServiceClient{
+		signer: signer,
+	}
+}
+
+// Auth resolve the envelope signer to its user.
+func (c *AuthServiceClient) Auth(req AuthReq) (*AuthResp, error) {
+	var res AuthResp
+	err := transport.Call("rpc/AuthService/Auth", c.signer, cryptographer.Metadata{
+		Domain: "auth",
+		Action: "login",
+	}, req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// Check the envelope signer is still a valid user.
+func (c *AuthServiceClient) Check(req CheckReq) (*CheckResp, error) {
+	var res CheckResp
+	err := transport.Call("rpc/AuthService/Check", c.signer, cryptographer.Metadata{
+		Domain: "auth",
+		Action: "check",
+	}, req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
@@ -1,7 +1,7 @@
 package service
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"orbital/pkg/cryptographer"
 	"orbital/web/wasm/domain"
@@ -9,6 +9,7 @@ import (
 	"orbital/web/wasm/pkg/dom"
 	"orbital/web/wasm/pkg/events"
 	"orbital/web/wasm/pkg/transport"
+	authrpc "orbital/web/wasm/service/auth"
 )
 
 const (
@@ -41,42 +42,24 @@ type User struct {
 }
 
 func (srv *AuthService) Login(req LoginReq) (*LoginRes, error) {
-
-	api := transport.NewAPI("rpc/AuthService/Auth")
-	api.WithMiddleware(transport.VerifyAndUnwrap)
-
 	sk, err := cryptographer.NewPrivateKeyFromHex(req.SecretKey)
 	if err != nil {
 		return nil, err
 	}
 
-	msg, err := cryptographer.Encode(sk, cryptographer.Metadata{
-		Domain: "auth",
-		Action: "login",
-	}, map[string]any{
-		"publicKey": sk.PublicKey().ToHex(),
-	})
-	if err != nil {
-		dom.ConsoleLog("msg", msg, "err", err.Error())
-		return nil, err
-	}
-
-	raw, err := json.Marshal(msg)
+	// The key is only stored once the node knows it
+	authRes, err := authrpc.NewAuthServiceClient(KeySigner(sk)).Auth(authrpc.AuthReq{})
 	if err != nil {
 		return nil, err
 	}
 
-	var (
-		res    *LoginRes
-		rawRes []byte
-	)
-	rawRes, err = api.Do(raw, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(rawRes, &res); err != nil {
-		return nil, err
+	res := &LoginRes{Code: authRes.Code, Error: authRes.Error}
+	if authRes.User != nil {
+		res.User = &User{
+			ID:     authRes.User.ID,
+			Name:   authRes.User.Name,
+			Access: authRes.User.Access,
+		}
 	}
 
 	if res.Error != nil {
@@ -101,6 +84,18 @@ func (srv *AuthService) Login(req LoginReq) (*LoginRes, error) {
 		return nil, err
 	}
 
+	// Bind the open websocket to the user now that a key is stored
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), transport.DefaultCallTimeout)
+		defer cancel()
+
+		// A reconnect may have authenticated it with the stored key already
+		var e *transport.Error
+		if _, err := srv.di.Ws.Authenticate(ctx); err != nil && !(errors.As(err, &e) && e.Type == "ws.alreadyAuthenticated") {
+			dom.ConsoleError("[login] cannot authenticate websocket", err.Error())
+		}
+	}()
+
 	return res, nil
 }
 
@@ -132,37 +127,11 @@ func (srv *AuthService) CheckKey(_ CheckKeyReq) (*CheckKeyRes, error) {
 		return &CheckKeyRes{Code: transport.Unauthenticated}, nil
 	}
 
-	api := transport.NewAPI("rpc/AuthService/Check")
-	api.WithMiddleware(transport.VerifyAndUnwrap)
-
-	msg, err := cryptographer.Encode(sk, cryptographer.Metadata{
-		Domain: "auth",
-		Action: "check",
-	}, nil)
-
-	if err != nil {
-		dom.ConsoleLog("msg", msg, "err", err.Error())
-		return nil, err
-	}
-
-	raw, err := json.Marshal(msg)
+	res, err := authrpc.NewAuthServiceClient(KeySigner(sk)).Check(authrpc.CheckReq{})
 	if err != nil {
 		return nil, err
 	}
 
-	var (
-		res    *CheckKeyRes
-		rawRes []byte
-	)
-	rawRes, err = api.Do(raw, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if err = json.Unmarshal(rawRes, &res); err != nil {
-		return nil, err
-	}
-
 	return &CheckKeyRes{Code: res.Code}, nil
 }
 
@@ -0,0 +1,53 @@
+// Code generated by orbital gen. DO NOT EDIT.
+// Source: MachineService schema
+
+package machine
+
+import (
+	"orbital/pkg/cryptographer"
+	"orbital/web/wasm/pkg/transport"
+)
+
+// Container managed container as listed by the docker daemon
+type Container struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Image   string `json:"image"`
+	State   string `json:"state"`  // created, running, exited...
+	Status  string `json:"status"` // Human readable, e.g. "Up 2 hours"
+	Created int64  `json:"created"`
+}
+
+type ContainersReq struct {
+}
+
+type ContainersResp struct {
+	Containers []Container              `json:"containers"`
+	Code       transport.Code           `json:"code"`
+	Error      *transport.ErrorResponse `json:"error,omitempty"`
+}
+
+// MachineServiceClient typed client for MachineService
+type MachineServiceClient struct {
+	signer transport.SignerFunc
+}
+
+func NewMachineServiceClient(signer transport.SignerFunc) *MachineServiceClient {
+	return &MachineServiceClient{
+		signer: signer,
+	}
+}
+
+// Containers List the containers managed by the node.
+func (c *MachineServiceClient) Containers(req ContainersReq) (*ContainersResp, error) {
+	var res ContainersResp
+	err := transport.Call("rpc/MachineService/Containers", c.signer, cryptographer.Metadata{
+		Domain: "machine",
+		Action: "containers",
+	}, req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,122 @@
+// Code generated by orbital gen. DO NOT EDIT.
+// Source: SessionsService schema
+
+package sessions
+
+import (
+	"orbital/pkg/cryptographer"
+	"orbital/web/wasm/pkg/transport"
+)
+
+// Session an open websocket connection
+type Session struct {
+	ConnID      string `json:"connId"`
+	UserID      string `json:"userId"` // Empty until the connection is authenticated
+	Role        string `json:"role"`
+	RemoteAddr  string `json:"remoteAddr"`
+	ConnectedAt int64  `json:"connectedAt"` // Unix seconds
+	LastPong    int64  `json:"lastPong"`    // Unix seconds of the last answered ping
+	BytesIn     uint64 `json:"bytesIn"`
+	BytesOut    uint64 `json:"bytesOut"`
+	MessagesIn  uint64 `json:"messagesIn"`
+	MessagesOut uint64 `json:"messagesOut"`
+	Dropped     uint64 `json:"dropped"` // Messages dropped because the send queue was full
+	Queued      int    `json:"queued"`
+}
+
+// Notice admin message broadcast on sessions/notice
+type Notice struct {
+	Message string `json:"message"`
+	Level   string `json:"level"` // info, warning or error
+	Time    int64  `json:"time"`  // Unix seconds
+}
+
+// Presence published on sessions/presence when an authenticated connection joins or leaves
+type Presence struct {
+	ConnID string   `json:"connId"`
+	UserID string   `json:"userId"`
+	Online bool     `json:"online"`
+	Users  []string `json:"users"` // Users online after the change
+}
+
+type ListReq struct {
+}
+
+type ListResp struct {
+	Sessions []Session                `json:"sessions"`
+	Code     transport.Code           `json:"code"`
+	Error    *transport.ErrorResponse `json:"error,omitempty"`
+}
+
+type KickReq struct {
+	ConnID string `json:"connId"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type KickResp struct {
+	Code  transport.Code           `json:"code"`
+	Error *transport.ErrorResponse `json:"error,omitempty"`
+}
+
+type NoticeReq struct {
+	Message string `json:"message"`
+	Level   string `json:"level,omitempty"` // Defaults to info
+}
+
+type NoticeResp struct {
+	Code  transport.Code           `json:"code"`
+	Error *transport.ErrorResponse `json:"error,omitempty"`
+}
+
+// SessionsServiceClient typed client for SessionsService
+type SessionsServiceClient struct {
+	signer transport.SignerFunc
+}
+
+func NewSessionsServiceClient(signer transport.SignerFunc) *SessionsServiceClient {
+	return &SessionsServiceClient{
+		signer: signer,
+	}
+}
+
+// List the open websocket connections.
+func (c *SessionsServiceClient) List(req ListReq) (*ListResp, error) {
+	var res ListResp
+	err := transport.Call("rpc/SessionsService/List", c.signer, cryptographer.Metadata{
+		Domain: "sessions",
+		Action: "list",
+	}, req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// Kick Close a websocket connection.
+func (c *SessionsServiceClient) Kick(req KickReq) (*KickResp, error) {
+	var res KickResp
+	err := transport.Call("rpc/SessionsService/Kick", c.signer, cryptographer.Metadata{
+		Domain: "sessions",
+		Action: "kick",
+	}, req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// Notice Broadcast an admin notice to every connection.
+func (c *SessionsServiceClient) Notice(req NoticeReq) (*NoticeResp, error) {
+	var res NoticeResp
+	err := transport.Call("rpc/SessionsService/Notice", c.signer, cryptographer.Metadata{
+		Domain: "sessions",
+		Action: "notice",
+	}, req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
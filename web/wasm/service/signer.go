@@ -0,0 +1,38 @@
+package service
+
+import (
+	"errors"
+	"orbital/pkg/cryptographer"
+	"orbital/web/wasm/domain"
+	"orbital/web/wasm/pkg/storage"
+	"orbital/web/wasm/pkg/transport"
+)
+
+// StoredSigner sign calls with the secret key saved at login
+func StoredSigner(db storage.Storage) transport.SignerFunc {
+	return func() (cryptographer.Signer, error) {
+		authRepo := domain.NewAuthRepository(db)
+		auth, err := authRepo.Get()
+		if err != nil {
+			if errors.Is(err, domain.ErrKeyNotFound) {
+				return nil, domain.ErrKeyNotFound
+			}
+
+			return nil, err
+		}
+
+		sk, err := cryptographer.NewPrivateKeyFromHex(auth.SecretKey)
+		if err != nil {
+			return nil, err
+		}
+
+		return sk, nil
+	}
+}
+
+// KeySigner sign calls with a key that is not stored yet
+func KeySigner(sk cryptographer.Signer) transport.SignerFunc {
+	return func() (cryptographer.Signer, error) {
+		return sk, nil
+	}
+}